@@ -0,0 +1,27 @@
+terraform {
+  required_providers {
+    intelcloud = {
+      source = "intel/intelcloud"
+      version = "0.0.6"
+    }
+  }
+}
+
+
+provider "intelcloud" {
+  region = "us-region-2"
+}
+
+resource "intelcloud_vnet" "example" {
+  name              = "tf-demo-vnet"
+  availability_zone = "us-region-2a"
+  prefix_length     = 24
+}
+
+data "intelcloud_vnets" "zone" {
+  availability_zone = intelcloud_vnet.example.availability_zone
+}
+
+output "vnets" {
+  value = data.intelcloud_vnets.zone.vnets
+}
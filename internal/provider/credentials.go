@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const defaultProfileName = "default"
+
+var errConflictingCredentials = errors.New("both an api token and client credentials are set")
+
+// clientCredentials are the secrets used to authenticate against the ITAC
+// API, either a client id and secret pair or a bearer token.
+type clientCredentials struct {
+	ClientId     string
+	ClientSecret string
+	APIToken     string
+	// ExpiresAt is the token expiry reported by the source, if any.
+	ExpiresAt time.Time
+	// Source is the credential provider the credentials were taken from.
+	Source credentialProvider
+}
+
+func (c *clientCredentials) empty() bool {
+	return c.ClientId == "" && c.ClientSecret == "" && c.APIToken == ""
+}
+
+func (c *clientCredentials) complete() bool {
+	return c.APIToken != "" || (c.ClientId != "" && c.ClientSecret != "")
+}
+
+// credentialProvider obtains credentials from a single source. Retrieve
+// returns empty credentials when the source has nothing to offer.
+type credentialProvider interface {
+	Name() string
+	Retrieve(ctx context.Context) (*clientCredentials, error)
+}
+
+// staticCredentialProvider offers credentials known up front, such as the
+// ones from the provider configuration, the environment or a profile.
+type staticCredentialProvider struct {
+	name  string
+	creds clientCredentials
+}
+
+func (p *staticCredentialProvider) Name() string {
+	return p.name
+}
+
+func (p *staticCredentialProvider) Retrieve(_ context.Context) (*clientCredentials, error) {
+	creds := p.creds
+	return &creds, nil
+}
+
+// execCredentialProvider runs an external command that prints credentials as
+// JSON on stdout, in the manner of kubectl exec credential plugins.
+type execCredentialProvider struct {
+	command string
+	args    []string
+	env     map[string]string
+}
+
+// execCredentialOutput is the JSON document an exec credential plugin prints.
+type execCredentialOutput struct {
+	ClientId            string `json:"client_id"`
+	ClientSecret        string `json:"client_secret"`
+	Token               string `json:"token"`
+	ExpirationTimestamp string `json:"expiration_timestamp"`
+}
+
+func (p *execCredentialProvider) Name() string {
+	return "exec command " + p.command
+}
+
+func (p *execCredentialProvider) Retrieve(ctx context.Context) (*clientCredentials, error) {
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Env = os.Environ()
+	for k, v := range p.env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	tflog.Debug(ctx, "running exec credential plugin", map[string]any{"command": p.command, "args": p.args})
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running %s: %v: %s", p.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	out := execCredentialOutput{}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("error parsing output of %s: %v", p.command, err)
+	}
+
+	creds := &clientCredentials{
+		ClientId:     out.ClientId,
+		ClientSecret: out.ClientSecret,
+		APIToken:     out.Token,
+	}
+	if out.ExpirationTimestamp != "" {
+		expiresAt, err := time.Parse(time.RFC3339, out.ExpirationTimestamp)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing expiration_timestamp of %s: %v", p.command, err)
+		}
+		creds.ExpiresAt = expiresAt
+	}
+	return creds, nil
+}
+
+// isExecProvider reports whether p runs an exec credential plugin.
+func isExecProvider(p credentialProvider) bool {
+	_, ok := p.(*execCredentialProvider)
+	return ok
+}
+
+// tokenSource adapts the command to an itacservices.TokenSource so that the
+// client runs it again whenever its token is about to expire.
+func (p *execCredentialProvider) tokenSource() itacservices.TokenSource {
+	return func(ctx context.Context) (string, time.Time, error) {
+		creds, err := p.Retrieve(ctx)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if creds.APIToken == "" {
+			return "", time.Time{}, fmt.Errorf("%s did not return a token", p.command)
+		}
+		return creds.APIToken, creds.ExpiresAt, nil
+	}
+}
+
+// credentialProviderError is the error of the credential provider that
+// failed to resolve the credentials.
+type credentialProviderError struct {
+	provider credentialProvider
+	err      error
+}
+
+func (e *credentialProviderError) Error() string {
+	return e.provider.Name() + ": " + e.err.Error()
+}
+
+func (e *credentialProviderError) Unwrap() error {
+	return e.err
+}
+
+// resolveCredentials walks the chain in order and returns the credentials of
+// the first provider offering any. A provider offering only part of a client
+// id and secret pair is completed from the providers after it.
+func resolveCredentials(ctx context.Context, chain []credentialProvider) (*clientCredentials, error) {
+	resolved := &clientCredentials{}
+	for _, p := range chain {
+		creds, err := p.Retrieve(ctx)
+		if err != nil {
+			return nil, &credentialProviderError{provider: p, err: err}
+		}
+		if creds.empty() {
+			continue
+		}
+		if creds.APIToken != "" && (creds.ClientId != "" || creds.ClientSecret != "") {
+			return nil, &credentialProviderError{provider: p, err: errConflictingCredentials}
+		}
+
+		if resolved.empty() {
+			*resolved = *creds
+			resolved.Source = p
+		} else if creds.APIToken == "" {
+			if resolved.ClientId == "" {
+				resolved.ClientId = creds.ClientId
+			}
+			if resolved.ClientSecret == "" {
+				resolved.ClientSecret = creds.ClientSecret
+			}
+		}
+
+		if resolved.complete() {
+			break
+		}
+	}
+	tflog.Debug(ctx, "resolved provider credentials", map[string]any{"complete": resolved.complete()})
+	return resolved, nil
+}
+
+// credentialsProfile holds the settings of a named profile in the shared
+// credentials file.
+type credentialsProfile struct {
+	Region       string
+	Cloudaccount string
+	ClientId     string
+	ClientSecret string
+	APIToken     string
+}
+
+// defaultCredentialsFile returns the location of the shared credentials file
+// used when none is configured, ~/.intelcloud/credentials.
+func defaultCredentialsFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".intelcloud", "credentials")
+}
+
+// loadCredentialsProfile reads the named profile from the credentials file.
+// A missing or malformed file or a missing profile is only an error when
+// required is set, otherwise an empty profile is returned.
+func loadCredentialsProfile(ctx context.Context, path, name string, required bool) (*credentialsProfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return &credentialsProfile{}, nil
+		}
+		return nil, fmt.Errorf("error opening credentials file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	profiles, err := parseCredentialsFile(f)
+	if err != nil {
+		if !required {
+			tflog.Warn(ctx, "skipping malformed credentials file", map[string]any{"path": path, "error": err.Error()})
+			return &credentialsProfile{}, nil
+		}
+		return nil, fmt.Errorf("error parsing credentials file %s: %v", path, err)
+	}
+
+	values, ok := profiles[name]
+	if !ok {
+		if required {
+			return nil, fmt.Errorf("profile %q not found in credentials file %s", name, path)
+		}
+		return &credentialsProfile{}, nil
+	}
+
+	return &credentialsProfile{
+		Region:       values["region"],
+		Cloudaccount: values["cloudaccount"],
+		ClientId:     values["clientid"],
+		ClientSecret: values["clientsecret"],
+		APIToken:     values["apitoken"],
+	}, nil
+}
+
+// parseCredentialsFile parses an INI or TOML style credentials file into
+// profiles keyed by section name. Keys are lower-cased with underscores and
+// dashes removed so that client_id, client-id and clientid are equivalent.
+func parseCredentialsFile(r io.Reader) (map[string]map[string]string, error) {
+	profiles := map[string]map[string]string{}
+	var current map[string]string
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: malformed section header", lineNum)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			name = strings.Trim(name, `"'`)
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNum)
+			}
+			if _, ok := profiles[name]; !ok {
+				profiles[name] = map[string]string{}
+			}
+			current = profiles[name]
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNum)
+		}
+		if current == nil {
+			return nil, fmt.Errorf("line %d: key outside of a profile section", lineNum)
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		key = strings.NewReplacer("_", "", "-", "").Replace(key)
+
+		value, err := parseCredentialsValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		current[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// parseCredentialsValue unquotes TOML basic and literal strings and returns
+// bare INI values with any inline comment removed. A comment starts with a #
+// or ; at the beginning of the value or after whitespace, so that secrets
+// containing these characters can be left unquoted.
+func parseCredentialsValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := 1
+		for ; end < len(value) && value[end] != '"'; end++ {
+			if value[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(value) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if err := checkTrailingComment(value[end+1:]); err != nil {
+			return "", err
+		}
+		return unescapeBasicString(value[1:end])
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'") + 1
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if err := checkTrailingComment(value[end+1:]); err != nil {
+			return "", err
+		}
+		return value[1:end], nil
+	default:
+		for i := 0; i < len(value); i++ {
+			if (value[i] == '#' || value[i] == ';') && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+				return strings.TrimSpace(value[:i]), nil
+			}
+		}
+		return value, nil
+	}
+}
+
+// unescapeBasicString replaces the escape sequences of the body of a TOML
+// basic string with the characters they stand for.
+func unescapeBasicString(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("unterminated escape sequence")
+		}
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", fmt.Errorf("invalid escape sequence %q", s[i-1:])
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape sequence %q", s[i-1:i+1+n])
+			}
+			b.WriteRune(rune(code))
+			i += n
+		default:
+			return "", fmt.Errorf("invalid escape sequence %q", s[i-1:i+1])
+		}
+	}
+	return b.String(), nil
+}
+
+// checkTrailingComment returns an error unless rest, the text after a quoted
+// value, is empty or a comment.
+func checkTrailingComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") && !strings.HasPrefix(rest, ";") {
+		return fmt.Errorf("unexpected %q after string", rest)
+	}
+	return nil
+}
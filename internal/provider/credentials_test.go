@@ -0,0 +1,495 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCredentialsFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    map[string]map[string]string
+		wantErr string
+	}{
+		{
+			name: "ini with bare values",
+			file: `
+[default]
+client_id = id
+client-secret = secret
+`,
+			want: map[string]map[string]string{
+				"default": {"clientid": "id", "clientsecret": "secret"},
+			},
+		},
+		{
+			name: "toml with quoted values",
+			file: `
+["default"]
+client_id = "id"
+client_secret = 'se"cret'
+apitoken = "to\"ken"
+`,
+			want: map[string]map[string]string{
+				"default": {"clientid": "id", "clientsecret": `se"cret`, "apitoken": `to"ken`},
+			},
+		},
+		{
+			name: "toml escapes",
+			file: `
+[default]
+apitoken = "a\tb\\c\u00e9\U0001F600\e"
+`,
+			want: map[string]map[string]string{
+				"default": {"apitoken": "a\tb\\c\u00e9\U0001F600\x1b"},
+			},
+		},
+		{
+			name:    "escape not in toml",
+			file:    "[default]\napitoken = \"a\\x41\"\n",
+			wantErr: `line 2: invalid escape sequence "\\x"`,
+		},
+		{
+			name:    "truncated unicode escape",
+			file:    "[default]\napitoken = \"a\\u00e\"\n",
+			wantErr: `line 2: invalid escape sequence "\\u00e"`,
+		},
+		{
+			name: "comments",
+			file: `
+# a comment
+; another comment
+[default]
+client_id = id # the client id
+client_secret = sec#ret ; the secret
+region = "us-region-1" # quoted
+cloudaccount = '123' ; quoted
+apitoken = # empty
+`,
+			want: map[string]map[string]string{
+				"default": {"clientid": "id", "clientsecret": "sec#ret", "region": "us-region-1", "cloudaccount": "123", "apitoken": ""},
+			},
+		},
+		{
+			name: "comment characters inside quotes",
+			file: `
+[default]
+client_secret = "a # b ; c" # comment
+`,
+			want: map[string]map[string]string{
+				"default": {"clientsecret": "a # b ; c"},
+			},
+		},
+		{
+			name: "several profiles",
+			file: `
+[default]
+region = us-region-1
+[staging]
+region = us-staging-1
+`,
+			want: map[string]map[string]string{
+				"default": {"region": "us-region-1"},
+				"staging": {"region": "us-staging-1"},
+			},
+		},
+		{
+			name: "duplicate profiles are merged",
+			file: `
+[default]
+region = us-region-1
+client_id = id
+[default]
+region = us-region-2
+`,
+			want: map[string]map[string]string{
+				"default": {"region": "us-region-2", "clientid": "id"},
+			},
+		},
+		{
+			name:    "key outside of a profile",
+			file:    "region = us-region-1\n",
+			wantErr: "line 1: key outside of a profile section",
+		},
+		{
+			name:    "malformed section header",
+			file:    "[default\n",
+			wantErr: "line 1: malformed section header",
+		},
+		{
+			name:    "empty section name",
+			file:    "[ ]\n",
+			wantErr: "line 1: empty section name",
+		},
+		{
+			name:    "missing equal sign",
+			file:    "[default]\nregion\n",
+			wantErr: "line 2: expected key = value",
+		},
+		{
+			name:    "unterminated basic string",
+			file:    "[default]\nregion = \"us-region-1\n",
+			wantErr: "line 2: unterminated string",
+		},
+		{
+			name:    "unterminated literal string",
+			file:    "[default]\nregion = 'us-region-1\n",
+			wantErr: "line 2: unterminated string",
+		},
+		{
+			name:    "text after a quoted string",
+			file:    "[default]\nregion = \"us\" region\n",
+			wantErr: `line 2: unexpected "region" after string`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCredentialsFile(strings.NewReader(tc.file))
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("parseCredentialsFile() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCredentialsFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseCredentialsFile() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadCredentialsProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(path, []byte(`
+[default]
+region = us-region-1
+cloudaccount = 123
+client_id = id
+client_secret = secret # not part of the secret
+
+[token]
+apitoken = "token"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+	malformed := filepath.Join(dir, "malformed")
+	if err := os.WriteFile(malformed, []byte("region = us-region-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		profile  string
+		required bool
+		want     *credentialsProfile
+		wantErr  bool
+	}{
+		{
+			name:    "default profile",
+			path:    path,
+			profile: "default",
+			want:    &credentialsProfile{Region: "us-region-1", Cloudaccount: "123", ClientId: "id", ClientSecret: "secret"},
+		},
+		{
+			name:     "named profile",
+			path:     path,
+			profile:  "token",
+			required: true,
+			want:     &credentialsProfile{APIToken: "token"},
+		},
+		{
+			name:    "missing profile",
+			path:    path,
+			profile: "other",
+			want:    &credentialsProfile{},
+		},
+		{
+			name:     "missing required profile",
+			path:     path,
+			profile:  "other",
+			required: true,
+			wantErr:  true,
+		},
+		{
+			name:    "missing file",
+			path:    missing,
+			profile: "default",
+			want:    &credentialsProfile{},
+		},
+		{
+			name:     "missing required file",
+			path:     missing,
+			profile:  "default",
+			required: true,
+			wantErr:  true,
+		},
+		{
+			name:    "malformed file",
+			path:    malformed,
+			profile: "default",
+			want:    &credentialsProfile{},
+		},
+		{
+			name:     "malformed required file",
+			path:     malformed,
+			profile:  "default",
+			required: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := loadCredentialsProfile(context.Background(), tc.path, tc.profile, tc.required)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("loadCredentialsProfile() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCredentialsProfile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("loadCredentialsProfile() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// countingCredentialProvider offers fixed credentials and counts how often
+// they are retrieved.
+type countingCredentialProvider struct {
+	name  string
+	creds clientCredentials
+	err   error
+	calls int
+}
+
+func (p *countingCredentialProvider) Name() string {
+	return p.name
+}
+
+func (p *countingCredentialProvider) Retrieve(_ context.Context) (*clientCredentials, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	creds := p.creds
+	return &creds, nil
+}
+
+func TestResolveCredentials(t *testing.T) {
+	tests := []struct {
+		name string
+		// config, env, profile and exec are the credentials of the chain,
+		// in this order.
+		config, env, profile, exec clientCredentials
+		execErr                    error
+
+		want       clientCredentials
+		wantSource string
+		wantErr    error
+		// wantFailed is the name of the provider failing with wantErr.
+		wantFailed string
+		// wantExecCalls is how often the last provider must be asked.
+		wantExecCalls int
+	}{
+		{
+			name:       "config first",
+			config:     clientCredentials{APIToken: "config-token"},
+			env:        clientCredentials{ClientId: "env-id", ClientSecret: "env-secret"},
+			profile:    clientCredentials{APIToken: "profile-token"},
+			exec:       clientCredentials{APIToken: "exec-token"},
+			want:       clientCredentials{APIToken: "config-token"},
+			wantSource: "config",
+		},
+		{
+			name:       "env before profile",
+			env:        clientCredentials{ClientId: "env-id", ClientSecret: "env-secret"},
+			profile:    clientCredentials{APIToken: "profile-token"},
+			exec:       clientCredentials{APIToken: "exec-token"},
+			want:       clientCredentials{ClientId: "env-id", ClientSecret: "env-secret"},
+			wantSource: "env",
+		},
+		{
+			name:       "profile before exec",
+			profile:    clientCredentials{APIToken: "profile-token"},
+			exec:       clientCredentials{APIToken: "exec-token"},
+			want:       clientCredentials{APIToken: "profile-token"},
+			wantSource: "profile",
+		},
+		{
+			name:          "exec last",
+			exec:          clientCredentials{APIToken: "exec-token"},
+			want:          clientCredentials{APIToken: "exec-token"},
+			wantSource:    "exec",
+			wantExecCalls: 1,
+		},
+		{
+			name:       "client id and secret merged",
+			config:     clientCredentials{ClientId: "config-id"},
+			env:        clientCredentials{ClientId: "env-id", ClientSecret: "env-secret"},
+			exec:       clientCredentials{APIToken: "exec-token"},
+			want:       clientCredentials{ClientId: "config-id", ClientSecret: "env-secret"},
+			wantSource: "config",
+		},
+		{
+			name:          "tokens of later sources not merged",
+			config:        clientCredentials{ClientSecret: "config-secret"},
+			env:           clientCredentials{APIToken: "env-token"},
+			exec:          clientCredentials{ClientId: "exec-id"},
+			want:          clientCredentials{ClientId: "exec-id", ClientSecret: "config-secret"},
+			wantSource:    "config",
+			wantExecCalls: 1,
+		},
+		{
+			name:          "incomplete",
+			profile:       clientCredentials{ClientId: "profile-id"},
+			want:          clientCredentials{ClientId: "profile-id"},
+			wantSource:    "profile",
+			wantExecCalls: 1,
+		},
+		{
+			name:          "none",
+			wantExecCalls: 1,
+		},
+		{
+			name:       "conflict",
+			env:        clientCredentials{APIToken: "env-token", ClientId: "env-id"},
+			wantErr:    errConflictingCredentials,
+			wantFailed: "env",
+		},
+		{
+			name:          "exec error",
+			execErr:       errors.New("exec failed"),
+			wantErr:       errors.New("exec: exec failed"),
+			wantFailed:    "exec",
+			wantExecCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			exec := &countingCredentialProvider{name: "exec", creds: tc.exec, err: tc.execErr}
+			chain := []credentialProvider{
+				&staticCredentialProvider{name: "config", creds: tc.config},
+				&staticCredentialProvider{name: "env", creds: tc.env},
+				&staticCredentialProvider{name: "profile", creds: tc.profile},
+				exec,
+			}
+
+			got, err := resolveCredentials(context.Background(), chain)
+			if exec.calls != tc.wantExecCalls {
+				t.Errorf("exec retrieved %d times, want %d", exec.calls, tc.wantExecCalls)
+			}
+			if tc.wantErr != nil {
+				if err == nil || (!errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error()) {
+					t.Fatalf("resolveCredentials() error = %v, want %v", err, tc.wantErr)
+				}
+				var provErr *credentialProviderError
+				if !errors.As(err, &provErr) || provErr.provider.Name() != tc.wantFailed {
+					t.Errorf("resolveCredentials() error = %v, want it to come from %s", err, tc.wantFailed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveCredentials() error = %v", err)
+			}
+
+			source := ""
+			if got.Source != nil {
+				source = got.Source.Name()
+			}
+			if source != tc.wantSource {
+				t.Errorf("resolveCredentials() source = %q, want %q", source, tc.wantSource)
+			}
+			got.Source = nil
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Errorf("resolveCredentials() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecCredentialProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		env     map[string]string
+		want    clientCredentials
+		wantErr string
+	}{
+		{
+			name:   "token",
+			script: `echo '{"token": "exec-token", "expiration_timestamp": "2024-05-01T12:00:00Z"}'`,
+			want:   clientCredentials{APIToken: "exec-token", ExpiresAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "client credentials",
+			script: `echo '{"client_id": "exec-id", "client_secret": "exec-secret"}'`,
+			want:   clientCredentials{ClientId: "exec-id", ClientSecret: "exec-secret"},
+		},
+		{
+			name:   "environment",
+			script: `echo "{\"token\": \"$TOKEN\"}"`,
+			env:    map[string]string{"TOKEN": "env-token"},
+			want:   clientCredentials{APIToken: "env-token"},
+		},
+		{
+			name:    "invalid json",
+			script:  `echo 'token'`,
+			wantErr: "error parsing output of sh",
+		},
+		{
+			name:    "invalid expiration",
+			script:  `echo '{"token": "exec-token", "expiration_timestamp": "tomorrow"}'`,
+			wantErr: "error parsing expiration_timestamp of sh",
+		},
+		{
+			name:    "failure",
+			script:  `echo 'no credentials' >&2; exit 1`,
+			wantErr: "no credentials",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &execCredentialProvider{command: "sh", args: []string{"-c", tc.script}, env: tc.env}
+			got, err := p.Retrieve(context.Background())
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Retrieve() error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Retrieve() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tc.want) {
+				t.Errorf("Retrieve() = %+v, want %+v", *got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecCredentialProviderTokenSource(t *testing.T) {
+	p := &execCredentialProvider{command: "sh", args: []string{"-c", `echo '{"client_id": "exec-id", "client_secret": "exec-secret"}'`}}
+	if _, _, err := p.tokenSource()(context.Background()); err == nil || !strings.Contains(err.Error(), "did not return a token") {
+		t.Errorf("tokenSource() error = %v, want a missing token error", err)
+	}
+}
@@ -8,14 +8,18 @@ import (
 
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -37,6 +41,7 @@ type filesystemResourceModel struct {
 	Status           types.String           `tfsdk:"status"`
 	ClusterInfo      types.Object           `tfsdk:"cluster_info"`
 	AccessInfo       types.Object           `tfsdk:"access_info"`
+	Timeouts         timeouts.Value         `tfsdk:"timeouts"`
 }
 
 // NewFilesystemResource is a helper function to simplify the provider implementation.
@@ -75,7 +80,7 @@ func (r *filesystemResource) Metadata(_ context.Context, req resource.MetadataRe
 }
 
 // Schema defines the schema for the resource.
-func (r *filesystemResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *filesystemResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -83,6 +88,9 @@ func (r *filesystemResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			},
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"cloudaccount": schema.StringAttribute{
 				Computed: true,
@@ -133,8 +141,14 @@ func (r *filesystemResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Computed: true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
-
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -148,6 +162,14 @@ func (r *filesystemResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultFilesystemTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	inArg := itacservices.FilesystemCreateRequest{
 		Metadata: struct {
 			Name string "json:\"name\""
@@ -241,6 +263,11 @@ func (r *filesystemResource) Read(ctx context.Context, req resource.ReadRequest,
 	// Get refreshed order value from IDC Service
 	filesystem, err := r.client.GetFilesystemByResourceId(ctx, orig.ID.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "filesystem not found, removing it from state", map[string]any{"id": orig.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC Filesystem resource",
 			"Could not read IDC Filesystem resource ID "+orig.ID.ValueString()+": "+err.Error(),
@@ -256,6 +283,7 @@ func (r *filesystemResource) Read(ctx context.Context, req resource.ReadRequest,
 		)
 		return
 	}
+	state.Timeouts = orig.Timeouts
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, state)
@@ -283,6 +311,14 @@ func (r *filesystemResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, itacservices.DefaultFilesystemTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	// Detect changes in the "spec" field
 	if !plan.Spec.Size.Equal(state.Spec.Size) {
 		tflog.Info(ctx, "Detected change in filesystem spec, updating resource")
@@ -308,7 +344,7 @@ func (r *filesystemResource) Update(ctx context.Context, req resource.UpdateRequ
 			},
 		}
 
-		tflog.Info(ctx, "making a call to IDC Service for update filesystem", map[string]any{"Payload": inArg})
+		tflog.Info(ctx, "making a call to IDC Service for update filesystem", map[string]any{"name": inArg.Metadata.Name, "size": inArg.Payload.Spec.Request.Size})
 		err := r.client.UpdateFilesystem(ctx, &inArg)
 		if err != nil {
 			resp.Diagnostics.AddError(
@@ -337,14 +373,19 @@ func (r *filesystemResource) Update(ctx context.Context, req resource.UpdateRequ
 			return
 		}
 		currState.Spec.Size = plan.Spec.Size
+		currState.Timeouts = plan.Timeouts
 		// Set refreshed state
 		diags = resp.State.Set(ctx, currState)
 		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
+		return
 	}
 	tflog.Info(ctx, "no change detected change in filesystem spec, skipping update")
+	state.Timeouts = plan.Timeouts
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
 }
 
 func (r *filesystemResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -362,12 +403,20 @@ func (r *filesystemResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultFilesystemTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteFilesystemByResourceId(ctx, state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
-			"Error Deleting IDC Instance resource",
-			"Could not delete IDC Instance resource ID "+state.ID.ValueString()+": "+err.Error(),
+			"Error Deleting IDC Filesystem resource",
+			"Could not delete IDC Filesystem resource ID "+state.ID.ValueString()+": "+err.Error(),
 		)
 		return
 	}
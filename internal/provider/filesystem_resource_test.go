@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccFilesystemResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckFilesystemDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccFilesystemResourceConfig(name, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_filesystem.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_filesystem.test", "spec.size_in_tb", "1"),
+					resource.TestCheckResourceAttr("intelcloud_filesystem.test", "spec.access_mode", "ReadWrite"),
+					resource.TestCheckResourceAttr("intelcloud_filesystem.test", "status", "ready"),
+					resource.TestCheckResourceAttrSet("intelcloud_filesystem.test", "id"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "intelcloud_filesystem.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update testing, the size is changed in place
+			{
+				Config: testAccFilesystemResourceConfig(name, 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_filesystem.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_filesystem.test", "spec.size_in_tb", "2"),
+			},
+			// Drift testing, a filesystem deleted outside of Terraform is recreated
+			{
+				Config:             testAccFilesystemResourceConfig(name, 2),
+				Check:              testAccDeleteOutOfBand("intelcloud_filesystem.test", deleteFilesystem),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccFilesystemResourceConfig(name, 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_filesystem.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccFilesystemResourceConfig(name string, size int) string {
+	return fmt.Sprintf(`
+resource "intelcloud_filesystem" "test" {
+  name = %[1]q
+  spec = {
+    size_in_tb = %[2]d
+  }
+}
+`, name, size)
+}
+
+func deleteFilesystem(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteFilesystemByResourceId(ctx, attrs["id"])
+}
+
+var testAccCheckFilesystemDestroy = testAccCheckDestroyed("intelcloud_filesystem", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetFilesystemByResourceId(ctx, attrs["id"])
+	return err
+})
@@ -2,23 +2,33 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &iksClusterResource{}
-	_ resource.ResourceWithConfigure = &iksClusterResource{}
+	_ resource.Resource                = &iksClusterResource{}
+	_ resource.ResourceWithConfigure   = &iksClusterResource{}
+	_ resource.ResourceWithImportState = &iksClusterResource{}
+	_ resource.ResourceWithModifyPlan  = &iksClusterResource{}
 )
 
 // orderKubernetesModel maps the resource schema data.
@@ -32,7 +42,8 @@ type iksClusterResourceModel struct {
 	UpgardeAvailable types.Bool   `tfsdk:"upgrade_available"`
 	// UpgradableVersions []types.String `tfsdk:"upgrade_k8s_versions_available"`
 
-	Storage *models.IKSStorage `tfsdk:"storage"`
+	Storage  *models.IKSStorage `tfsdk:"storage"`
+	Timeouts timeouts.Value     `tfsdk:"timeouts"`
 }
 
 // NewIKSClusterResource is a helper function to simplify the provider implementation.
@@ -71,11 +82,14 @@ func (r *iksClusterResource) Metadata(_ context.Context, req resource.MetadataRe
 }
 
 // Schema defines the schema for the resource.
-func (r *iksClusterResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *iksClusterResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -100,6 +114,13 @@ func (r *iksClusterResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			},
 			"storage": schema.SingleNestedAttribute{
 				Optional: true,
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplaceIf(
+						storageRequiresReplace,
+						"The storage of a cluster cannot be added, resized or removed once the cluster is created.",
+						"The storage of a cluster cannot be added, resized or removed once the cluster is created.",
+					),
+				},
 				Attributes: map[string]schema.Attribute{
 					"size_in_tb": schema.Int64Attribute{
 						Required: true,
@@ -120,6 +141,13 @@ func (r *iksClusterResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			// 	Computed:    true,
 			// },
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -134,6 +162,15 @@ func (r *iksClusterResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	// The create timeout covers both the cluster and its storage.
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultIKSClusterTimeout+itacservices.DefaultIKSStorageTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	inArg := itacservices.IKSCreateRequest{
 		Name:         plan.Name.ValueString(),
 		K8sVersion:   plan.K8sversion.ValueString(),
@@ -142,10 +179,7 @@ func (r *iksClusterResource) Create(ctx context.Context, req resource.CreateRequ
 	}
 	iksClusterResp, cloudaccount, err := r.client.CreateIKSCluster(ctx, &inArg, false)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating order",
-			"Could not create order, unexpected error: "+err.Error(),
-		)
+		addCreateFailure(ctx, req, resp, path.Root("id"), path.Root("cluster_status"), "Error creating order", "Could not create order", err)
 		return
 	}
 
@@ -170,32 +204,28 @@ func (r *iksClusterResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	if plan.Storage != nil && !plan.Storage.Size.IsNull() {
-		inArg := itacservices.IKSStorageCreateRequest{
-			Enable: true,
-			Size:   fmt.Sprintf("%sTB", strconv.FormatInt(plan.Storage.Size.ValueInt64(), 10)),
-		}
+	plan.UpgardeAvailable = types.BoolValue(iksClusterResp.UpgradeAvailable)
 
-		storageResp, _, err := r.client.CreateIKSStorage(ctx, &inArg, plan.ID.ValueString())
+	if plan.Storage != nil && !plan.Storage.Size.IsNull() {
+		storage, err := r.createStorage(ctx, plan.ID.ValueString(), plan.Storage.Size.ValueInt64())
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error creating iks file storage",
-				"Could not create iks file storage, unexpected error: "+err.Error(),
-			)
+			// The cluster exists, keep it in the state without its storage.
+			plan.Storage = nil
+			resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+			if errors.Is(err, context.Canceled) {
+				resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateCreating, []byte("true"))...)
+				resp.Diagnostics.AddWarning(
+					"Create interrupted",
+					"Creating the file storage of iks cluster "+plan.ID.ValueString()+" was interrupted. The next apply resumes it.",
+				)
+				return
+			}
+			addCreateError(&resp.Diagnostics, "Error creating iks file storage", "Could not create iks file storage", err)
 			return
 		}
-
-		sizeNum, _ := strconv.ParseInt(storageResp.Size, 10, 64)
-		currV := models.IKSStorage{
-			Size:            types.Int64Value(sizeNum),
-			State:           types.StringValue(storageResp.State),
-			StorageProvider: types.StringValue(storageResp.Provider),
-		}
-		plan.Storage = &currV
+		plan.Storage = storage
 	}
 
-	plan.UpgardeAvailable = types.BoolValue(iksClusterResp.UpgradeAvailable)
-
 	// for _, k := range iksClusterResp.UpgradableK8sVersions {
 	// 	plan.KubernetesCluster.UpgradableVersions = append(plan.KubernetesCluster.UpgradableVersions, types.StringValue(k))
 	// }
@@ -211,16 +241,27 @@ func (r *iksClusterResource) Create(ctx context.Context, req resource.CreateRequ
 // Read refreshes the Terraform state with the latest data.
 func (r *iksClusterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 
-	// Get current state
-	var state iksClusterResourceModel
-	diags := req.State.Get(ctx, &state)
+	// Get current state. Only the id is known after an import.
+	var id types.String
+	diags := req.State.GetAttribute(ctx, path.Root("id"), &id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var t timeouts.Value
+	diags = req.State.GetAttribute(ctx, path.Root("timeouts"), &t)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	iksClusterResp, cloudaccount, err := r.client.GetIKSClusterByClusterUUID(ctx, state.ID.ValueString())
+	iksClusterResp, cloudaccount, err := r.client.GetIKSClusterByClusterUUID(ctx, id.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "iks cluster not found, removing it from state", map[string]any{"id": id.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error reading state",
 			"Could not read state, unexpected error: "+err.Error(),
@@ -228,76 +269,50 @@ func (r *iksClusterResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Map response body to schema and populate Computed attribute values
-	state.ID = types.StringValue(iksClusterResp.ResourceId)
-	state.ClusterStatus = types.StringValue(iksClusterResp.ClusterState)
-	state.K8sversion = types.StringValue(iksClusterResp.K8sVersion)
-	if cloudaccount != nil {
-		state.Cloudaccount = types.StringValue(*cloudaccount)
-	} else {
-		state.Cloudaccount = types.StringNull()
+	state, err := refreshIKSCLusterResourceModel(ctx, iksClusterResp, cloudaccount)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading state",
+			"Could not read state, unexpected error: "+err.Error(),
+		)
+		return
 	}
+	state.Timeouts = t
 
-	network := models.ClusterNetwork{
-		ClusterCIDR: types.StringValue(iksClusterResp.Network.ClusterCIDR),
-		ClusterDNS:  types.StringValue(iksClusterResp.Network.ClusterDNS),
-		EnableLB:    types.BoolValue(iksClusterResp.Network.EnableLB),
-		ServiceCIDR: types.StringValue(iksClusterResp.Network.ServcieCIDR),
-	}
-	state.Network, diags = types.ObjectValueFrom(ctx, network.AttributeTypes(), network)
+	// Set refreshed state
+	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	// volumes := []models.IKSStorage{}
-	// for _, v := range iksClusterResp.Storages {
-	// 	currV := models.IKSStorage{
-	// 		Size:            types.StringValue(v.Size),
-	// 		State:           types.StringValue(v.State),
-	// 		StorageProvider: types.StringValue(v.Provider),
-	// 	}
-	// 	volumes = append(volumes, currV)
-	// }
-	// state.KubernetesCluster.Storage, diags = types.ListValueFrom(ctx, types.ObjectType{}.WithAttributeTypes(models.IKStorageAttributes), volumes)
-	// resp.Diagnostics.Append(diags...)
-	// if resp.Diagnostics.HasError() {
-	// 	return
-	// }
-
-	// ngs := []models.NodeGroup{}
-	// for _, n := range iksClusterResp.NodeGroups {
-	// 	ng := models.NodeGroup{
-	// 		ID:           types.StringValue(n.ID),
-	// 		Name:         types.StringValue(n.Name),
-	// 		Count:        types.Int64Value(n.Count),
-	// 		InstanceType: types.StringValue(n.InstanceType),
-	// 		IMIId:        types.StringValue(n.IMIID),
-	// 		State:        types.StringValue(n.State),
-	// 		UserDataURL:  types.StringValue(n.UserDataURL),
-	// 	}
-	// 	for _, sshk := range n.SSHKeyNames {
-	// 		state.KubernetesCluster.SSHPublicKeyNames = append(state.KubernetesCluster.SSHPublicKeyNames, types.StringValue(sshk.Name))
-	// 	}
-	// 	ngs = append(ngs, ng)
-	// }
-	// state.KubernetesCluster.NodeGroups, diags = types.ListValueFrom(ctx, types.ObjectType{}.WithAttributeTypes(models.NodeGroupAttributes), ngs)
-	// resp.Diagnostics.Append(diags...)
-	// if resp.Diagnostics.HasError() {
-	// 	return
-	// }
-	state.UpgardeAvailable = types.BoolValue(iksClusterResp.UpgradeAvailable)
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+// ModifyPlan plans the update resuming an interrupted create, which sets the
+// attributes known once the cluster and its storage are ready.
+func (r *iksClusterResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
 		return
 	}
+	paths := []path.Path{
+		path.Root("cluster_status"),
+		path.Root("network"),
+		path.Root("upgrade_available"),
+	}
+	var storage *models.IKSStorage
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("storage"), &storage)...)
+	if storage != nil {
+		paths = append(paths, path.Root("storage").AtName("state"), path.Root("storage").AtName("storage_provider"))
+	}
+	planResumedCreate(ctx, req, resp, paths...)
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *iksClusterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if resumingCreate(ctx, req.Private, &resp.Diagnostics) {
+		r.resumeCreate(ctx, req, resp)
+		return
+	}
+
 	var plan, state iksClusterResourceModel
 
 	// Retrieve the desired configuration from the plan
@@ -314,6 +329,14 @@ func (r *iksClusterResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, itacservices.DefaultIKSClusterTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
 	if !plan.K8sversion.Equal(state.K8sversion) {
 		tflog.Info(ctx, "Detected change in iks cluster spec for k8s version, updating cluster",
 			map[string]any{"current version ": state.K8sversion.ValueString(), "new version": plan.K8sversion.ValueString()})
@@ -349,6 +372,7 @@ func (r *iksClusterResource) Update(ctx context.Context, req resource.UpdateRequ
 		)
 		return
 	}
+	currState.Timeouts = plan.Timeouts
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, currState)
@@ -356,7 +380,55 @@ func (r *iksClusterResource) Update(ctx context.Context, req resource.UpdateRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	currState, err = refreshIKSCLusterResourceModel(ctx, cluster, cloudaccount)
+}
+
+// resumeCreate waits for a cluster whose create was interrupted and creates
+// its storage if that was not done yet.
+func (r *iksClusterResource) resumeCreate(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan iksClusterResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The id is unknown in the plan, the cluster being created has it in
+	// the state.
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &plan.ID)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultIKSClusterTimeout+itacservices.DefaultIKSStorageTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	tflog.Info(ctx, "resuming the create of iks cluster", map[string]any{"id": plan.ID.ValueString()})
+	cluster, err := r.client.WaitForIKSCluster(ctx, plan.ID.ValueString())
+	if err != nil {
+		addCreateError(&resp.Diagnostics, "Error creating order", "Could not create order", err)
+		return
+	}
+
+	if plan.Storage != nil && !plan.Storage.Size.IsNull() && len(cluster.Storages) == 0 {
+		if _, err := r.createStorage(ctx, plan.ID.ValueString(), plan.Storage.Size.ValueInt64()); err != nil {
+			addCreateError(&resp.Diagnostics, "Error creating iks file storage", "Could not create iks file storage", err)
+			return
+		}
+	}
+
+	cluster, cloudaccount, err := r.client.GetIKSClusterByClusterUUID(ctx, plan.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading IKS Cluster resource",
+			"Could not read IKS Cluster resource ID "+plan.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+	currState, err := refreshIKSCLusterResourceModel(ctx, cluster, cloudaccount)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading IKS cluster resource",
@@ -364,14 +436,54 @@ func (r *iksClusterResource) Update(ctx context.Context, req resource.UpdateRequ
 		)
 		return
 	}
+	currState.Timeouts = plan.Timeouts
 
-	// Set refreshed state
-	diags = resp.State.Set(ctx, currState)
-	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateCreating, nil)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, currState)...)
+}
+
+// storageRequiresReplace replaces a cluster whose storage is added, resized
+// or removed, except when resuming an interrupted create, which adds the
+// storage that was not created yet.
+func storageRequiresReplace(ctx context.Context, req planmodifier.ObjectRequest, resp *objectplanmodifier.RequiresReplaceIfFuncResponse) {
+	var plan, state *models.IKSStorage
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path, &plan)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, req.Path, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	tflog.Info(ctx, "no change detected change in cluster spec, skipping update")
+	// The computed attributes of the storage are unknown in the plan of
+	// any update, only its size is configured.
+	if plan != nil && state != nil && plan.Size.Equal(state.Size) {
+		return
+	}
+	resp.RequiresReplace = !resumingCreate(ctx, req.Private, &resp.Diagnostics)
+}
+
+// createStorage creates the file storage of size TB of the cluster and
+// waits for it to be active.
+func (r *iksClusterResource) createStorage(ctx context.Context, clusterUUID string, size int64) (*models.IKSStorage, error) {
+	inArg := itacservices.IKSStorageCreateRequest{
+		Enable: true,
+		Size:   fmt.Sprintf("%sTB", strconv.FormatInt(size, 10)),
+	}
+
+	storageResp, _, err := r.client.CreateIKSStorage(ctx, &inArg, clusterUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	sizeNum, _ := strconv.ParseInt(strings.TrimSuffix(storageResp.Size, "TB"), 10, 64)
+	return &models.IKSStorage{
+		Size:            types.Int64Value(sizeNum),
+		State:           types.StringValue(storageResp.State),
+		StorageProvider: types.StringValue(storageResp.Provider),
+	}, nil
+}
+
+func (r *iksClusterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to id attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -384,9 +496,17 @@ func (r *iksClusterResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultIKSClusterTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteIKSCluster(ctx, state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error Deleting IDC IKS Cluster resource",
 			"Could not delete IDC IDC IKS Cluster ID "+state.ID.String()+": "+err.Error(),
@@ -420,5 +540,17 @@ func refreshIKSCLusterResourceModel(ctx context.Context, cluster *itacservices.I
 		return state, fmt.Errorf("error parsing values")
 	}
 
+	// The resource manages at most one storage per cluster.
+	if len(cluster.Storages) > 0 {
+		storage := cluster.Storages[0]
+		sizeNum, _ := strconv.ParseInt(strings.TrimSuffix(storage.Size, "TB"), 10, 64)
+		state.Storage = &models.IKSStorage{
+			Size:            types.Int64Value(sizeNum),
+			State:           types.StringValue(storage.State),
+			StorageProvider: types.StringValue(storage.Provider),
+		}
+	}
+	state.UpgardeAvailable = types.BoolValue(cluster.UpgradeAvailable)
+
 	return state, nil
 }
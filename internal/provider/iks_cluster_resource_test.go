@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/itacfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccIKSClusterResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckIKSClusterDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccIKSClusterResourceConfig(name, "1.27", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "kubernetes_version", "1.27"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "cluster_status", "Active"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "storage.size_in_tb", "1"),
+					resource.TestCheckResourceAttrSet("intelcloud_iks_cluster.test", "id"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "intelcloud_iks_cluster.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update testing, a new kubernetes version upgrades the cluster
+			{
+				Config: testAccIKSClusterResourceConfig(name, "1.28", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_cluster.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "kubernetes_version", "1.28"),
+			},
+			// Update testing, storage of another size replaces the cluster
+			{
+				Config: testAccIKSClusterResourceConfig(name, "1.28", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_cluster.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "kubernetes_version", "1.28"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "storage.size_in_tb", "2"),
+				),
+			},
+			// Drift testing, a cluster deleted outside of Terraform is recreated
+			{
+				Config:             testAccIKSClusterResourceConfig(name, "1.28", 2),
+				Check:              testAccDeleteOutOfBand("intelcloud_iks_cluster.test", deleteIKSCluster),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccIKSClusterResourceConfig(name, "1.28", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_cluster.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccIKSClusterResource_interruptedCreate(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	const clustersPath = "/v1/cloudaccounts/*/iks/clusters"
+	var posts int
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+			posts = testAccFakeAPI.RequestCount(http.MethodPost, clustersPath)
+			testAccFakeAPI.SetPhaseBehavior(itacfake.KindCluster, name, itacfake.PhaseStuck)
+		},
+		// The create is interrupted while waiting for the cluster.
+		ProtoV6ProviderFactories: testAccInterruptingProviderFactories("intelcloud_iks_cluster", http.MethodGet, clustersPath+"/*"),
+		CheckDestroy:             testAccCheckIKSClusterDestroy,
+		Steps: []resource.TestStep{
+			// The cluster being created is saved to resume waiting for it
+			{
+				Config: testAccIKSClusterResourceConfig(name, "1.28", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("intelcloud_iks_cluster.test", "id"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "cluster_status", "Pending"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			// The next apply updates it and adds its storage once ready
+			// instead of creating another
+			{
+				PreConfig: func() {
+					testAccFakeAPI.SetPhaseBehavior(itacfake.KindCluster, name, itacfake.PhaseNormal)
+				},
+				Config: testAccIKSClusterResourceConfig(name, "1.28", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_cluster.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "cluster_status", "Active"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "storage.size_in_tb", "1"),
+					resource.TestCheckResourceAttr("intelcloud_iks_cluster.test", "storage.state", "Active"),
+					func(*terraform.State) error {
+						if n := testAccFakeAPI.RequestCount(http.MethodPost, clustersPath) - posts; n != 1 {
+							return fmt.Errorf("cluster created with %d requests, expected 1", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccIKSClusterResourceConfig(name, version string, storageSize int) string {
+	return fmt.Sprintf(`
+resource "intelcloud_iks_cluster" "test" {
+  name               = %[1]q
+  kubernetes_version = %[2]q
+  storage = {
+    size_in_tb = %[3]d
+  }
+}
+`, name, version, storageSize)
+}
+
+func deleteIKSCluster(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteIKSCluster(ctx, attrs["id"])
+}
+
+var testAccCheckIKSClusterDestroy = testAccCheckDestroyed("intelcloud_iks_cluster", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, _, err := client.GetIKSClusterByClusterUUID(ctx, attrs["id"])
+	return err
+})
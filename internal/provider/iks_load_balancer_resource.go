@@ -6,22 +6,31 @@ import (
 	"strconv"
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &iksLBResource{}
-	_ resource.ResourceWithConfigure = &iksLBResource{}
+	_ resource.Resource                = &iksLBResource{}
+	_ resource.ResourceWithConfigure   = &iksLBResource{}
+	_ resource.ResourceWithImportState = &iksLBResource{}
 )
 
 // orderIKSNodeGroupModel maps the resource schema data.
 type iksLBResourceModel struct {
 	ClusterUUID   types.String             `tfsdk:"cluster_uuid"`
 	LoadBalancers []models.IKSLoadBalancer `tfsdk:"load_balancers"`
+	Timeouts      timeouts.Value           `tfsdk:"timeouts"`
 }
 
 // NewIKSLB is a helper function to simplify the provider implementation.
@@ -60,14 +69,20 @@ func (r *iksLBResource) Metadata(_ context.Context, req resource.MetadataRequest
 }
 
 // Schema defines the schema for the resource.
-func (r *iksLBResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *iksLBResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"cluster_uuid": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"load_balancers": schema.ListNestedAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"id": schema.StringAttribute{
@@ -95,6 +110,12 @@ func (r *iksLBResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -109,6 +130,14 @@ func (r *iksLBResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultIKSLoadBalancerTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	for idx := range plan.LoadBalancers {
 		inArg := itacservices.IKSLoadBalancerRequest{
 			Name:    plan.LoadBalancers[idx].Name.ValueString(),
@@ -125,7 +154,7 @@ func (r *iksLBResource) Create(ctx context.Context, req resource.CreateRequest,
 			return
 		}
 
-		plan.LoadBalancers[idx].ID = types.StringValue(strconv.FormatInt(ilbResp.ID, 64))
+		plan.LoadBalancers[idx].ID = types.StringValue(strconv.FormatInt(ilbResp.ID, 10))
 		plan.LoadBalancers[idx].PoolPort = types.Int64Value(int64(ilbResp.PoolPort))
 		plan.LoadBalancers[idx].VipState = types.StringValue(ilbResp.VIPState)
 		plan.LoadBalancers[idx].VipIp = types.StringValue(ilbResp.VIPIP)
@@ -149,20 +178,53 @@ func (r *iksLBResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	for idx, lb := range state.LoadBalancers {
+	// After an import only the cluster is known, manage all of its load
+	// balancers.
+	if state.LoadBalancers == nil {
+		lbs, err := r.client.GetIKSLoadBalancerByClusterUUID(ctx, state.ClusterUUID.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading IDC Compute IKS Load Balancer resource",
+				"Could not read IDC Compute IKS Load Balancer resource ID "+state.ClusterUUID.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+		for _, lb := range lbs.Items {
+			state.LoadBalancers = append(state.LoadBalancers, models.IKSLoadBalancer{
+				ID:      types.StringValue(strconv.FormatInt(lb.ID, 10)),
+				Name:    types.StringValue(lb.Name),
+				Port:    types.Int64Value(int64(lb.Port)),
+				VipType: types.StringValue(lb.VIPType),
+			})
+		}
+	}
+
+	refreshed := []models.IKSLoadBalancer{}
+	for _, lb := range state.LoadBalancers {
 		vipIdNum, _ := strconv.ParseInt(lb.ID.ValueString(), 10, 64)
 		refreshedState, err := r.client.GetIKSLoadBalancerByID(ctx, state.ClusterUUID.ValueString(), vipIdNum)
 		if err != nil {
+			if common.IsNotFound(err) {
+				tflog.Warn(ctx, "iks load balancer not found, removing it from state", map[string]any{"cluster": state.ClusterUUID.ValueString(), "id": lb.ID.ValueString()})
+				continue
+			}
 			resp.Diagnostics.AddError(
 				"Error Reading IDC Compute IKS Load Balancer resource",
 				"Could not read IDC Compute IKS Load Balancer resource ID "+state.ClusterUUID.ValueString()+": "+err.Error(),
 			)
 			return
 		}
-		state.LoadBalancers[idx].PoolPort = types.Int64Value(int64(refreshedState.PoolPort))
-		state.LoadBalancers[idx].VipIp = types.StringValue(refreshedState.VIPIP)
-		state.LoadBalancers[idx].VipState = types.StringValue(refreshedState.VIPState)
+		lb.PoolPort = types.Int64Value(int64(refreshedState.PoolPort))
+		lb.VipIp = types.StringValue(refreshedState.VIPIP)
+		lb.VipState = types.StringValue(refreshedState.VIPState)
+		refreshed = append(refreshed, lb)
 	}
+	if len(refreshed) == 0 {
+		tflog.Warn(ctx, "no iks load balancer found, removing the resource from state", map[string]any{"cluster": state.ClusterUUID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	state.LoadBalancers = refreshed
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -173,10 +235,45 @@ func (r *iksLBResource) Read(ctx context.Context, req resource.ReadRequest, resp
 
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update only changes the timeouts, every other configurable attribute of the
+// load balancers requires their replacement.
 func (r *iksLBResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
 func (r *iksLBResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Get current state
+	var state iksLBResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultIKSLoadBalancerTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	for _, lb := range state.LoadBalancers {
+		vipIdNum, _ := strconv.ParseInt(lb.ID.ValueString(), 10, 64)
+		err := r.client.DeleteIKSLoadBalancer(ctx, state.ClusterUUID.ValueString(), vipIdNum)
+		if err != nil && !common.IsNotFound(err) {
+			resp.Diagnostics.AddError(
+				"Error Deleting IDC IKS load balancer resource",
+				"Could not delete IDC IKS load balancer resource ID "+lb.ID.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+	}
+}
+
+// ImportState imports all the load balancers of a cluster by the cluster
+// UUID.
+func (r *iksLBResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("cluster_uuid"), req, resp)
 }
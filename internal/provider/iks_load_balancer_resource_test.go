@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccIKSLBResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckIKSLBDestroy,
+			testAccCheckIKSClusterDestroy,
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccIKSLBResourceConfig(name, 80),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_iks_lb.test", "load_balancers.#", "1"),
+					resource.TestCheckResourceAttr("intelcloud_iks_lb.test", "load_balancers.0.name", name),
+					resource.TestCheckResourceAttr("intelcloud_iks_lb.test", "load_balancers.0.port", "80"),
+					resource.TestCheckResourceAttr("intelcloud_iks_lb.test", "load_balancers.0.vip_state", "Active"),
+					resource.TestCheckResourceAttrSet("intelcloud_iks_lb.test", "load_balancers.0.id"),
+					resource.TestCheckResourceAttrSet("intelcloud_iks_lb.test", "load_balancers.0.vip_ip"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "intelcloud_iks_lb.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccImportStateIDFunc("intelcloud_iks_lb.test", "cluster_uuid"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "cluster_uuid",
+			},
+			// Update testing, a load balancer on another port replaces the old one
+			{
+				Config: testAccIKSLBResourceConfig(name, 443),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_lb.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_iks_lb.test", "load_balancers.0.port", "443"),
+			},
+			// Drift testing, load balancers deleted outside of Terraform are recreated
+			{
+				Config:             testAccIKSLBResourceConfig(name, 443),
+				Check:              testAccDeleteOutOfBand("intelcloud_iks_lb.test", deleteIKSLB),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccIKSLBResourceConfig(name, 443),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_lb.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccIKSLBResourceConfig(name string, port int) string {
+	return fmt.Sprintf(`
+resource "intelcloud_iks_cluster" "test" {
+  name               = %[1]q
+  kubernetes_version = "1.28"
+}
+
+resource "intelcloud_iks_lb" "test" {
+  cluster_uuid = intelcloud_iks_cluster.test.id
+  load_balancers = [{
+    name     = %[1]q
+    port     = %[2]d
+    vip_type = "public"
+  }]
+}
+`, name, port)
+}
+
+func deleteIKSLB(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	id, _ := strconv.ParseInt(attrs["load_balancers.0.id"], 10, 64)
+	return client.DeleteIKSLoadBalancer(ctx, attrs["cluster_uuid"], id)
+}
+
+var testAccCheckIKSLBDestroy = testAccCheckDestroyed("intelcloud_iks_lb", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	id, _ := strconv.ParseInt(attrs["load_balancers.0.id"], 10, 64)
+	_, err := client.GetIKSLoadBalancerByID(ctx, attrs["cluster_uuid"], id)
+	return err
+})
@@ -3,18 +3,28 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &iksNodeGroupResource{}
-	_ resource.ResourceWithConfigure = &iksNodeGroupResource{}
+	_ resource.Resource                = &iksNodeGroupResource{}
+	_ resource.ResourceWithConfigure   = &iksNodeGroupResource{}
+	_ resource.ResourceWithImportState = &iksNodeGroupResource{}
 )
 
 // iksNodeGroupResourceModel maps the resource schema data.
@@ -29,6 +39,7 @@ type iksNodeGroupResourceModel struct {
 	UserDataURL       types.String                  `tfsdk:"userdata_url"`
 	SSHPublicKeyNames []types.String                `tfsdk:"ssh_public_key_names"`
 	Interfaces        []models.NetworkInterfaceSpec `tfsdk:"interfaces"`
+	Timeouts          timeouts.Value                `tfsdk:"timeouts"`
 }
 
 // NewOrderKubernetes is a helper function to simplify the provider implementation.
@@ -67,7 +78,7 @@ func (r *iksNodeGroupResource) Metadata(_ context.Context, req resource.Metadata
 }
 
 // Schema defines the schema for the resource.
-func (r *iksNodeGroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *iksNodeGroupResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -75,15 +86,27 @@ func (r *iksNodeGroupResource) Schema(_ context.Context, _ resource.SchemaReques
 			},
 			"cluster_uuid": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"node_count": schema.Int64Attribute{
 				Required: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
 			},
 			"node_type": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"imiid": schema.StringAttribute{
 				Computed: true,
@@ -93,25 +116,42 @@ func (r *iksNodeGroupResource) Schema(_ context.Context, _ resource.SchemaReques
 			},
 			"userdata_url": schema.StringAttribute{
 				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"ssh_public_key_names": schema.ListAttribute{
 				ElementType: types.StringType,
 				Required:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
 			},
 			"interfaces": schema.ListNestedAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Required: true,
+							Required:    true,
+							Description: "The availability zone of the interface, such as us-region-1a.",
 						},
 						"vnet": schema.StringAttribute{
-							Required: true,
+							Required:    true,
+							Description: "The name of the vnet of the interface, in the availability zone.",
 						},
 					},
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
@@ -126,6 +166,14 @@ func (r *iksNodeGroupResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultIKSNodeGroupTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	inArg := itacservices.IKSNodeGroupCreateRequest{
 		Name:           plan.Name.ValueString(),
 		Count:          plan.Count.ValueInt64(),
@@ -139,22 +187,15 @@ func (r *iksNodeGroupResource) Create(ctx context.Context, req resource.CreateRe
 	}
 
 	for _, inf := range plan.Interfaces {
-		inArg.Interfaces = append(inArg.Interfaces,
-			struct {
-				AvailabilityZone string "json:\"availabilityzonename\""
-				VNet             string "json:\"networkinterfacevnetname\""
-			}{
-				AvailabilityZone: inf.Name.ValueString(),
-				VNet:             inf.VNet.ValueString(),
-			})
+		inArg.Interfaces = append(inArg.Interfaces, itacservices.IKSVNet{
+			AvailabilityZone: inf.Name.ValueString(),
+			VNet:             inf.VNet.ValueString(),
+		})
 	}
 
 	nodeGroupResp, _, err := r.client.CreateIKSNodeGroup(ctx, &inArg, plan.ClusterUUID.ValueString(), false)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating iks node group",
-			"Could not create iks node group, unexpected error: "+err.Error(),
-		)
+		addCreateError(&resp.Diagnostics, "Error creating iks node group", "Could not create iks node group", err)
 		return
 	}
 
@@ -183,6 +224,11 @@ func (r *iksNodeGroupResource) Read(ctx context.Context, req resource.ReadReques
 	// Get refreshed order value from IDC Service
 	ngState, _, err := r.client.GetIKSNodeGroupByID(ctx, state.ClusterUUID.ValueString(), state.ID.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "iks node group not found, removing it from state", map[string]any{"id": state.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC Compute IKS Node Group resource",
 			"Could not read IDC Compute IKS Node Group resource ID "+state.ID.ValueString()+": "+err.Error(),
@@ -190,8 +236,25 @@ func (r *iksNodeGroupResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	state.Name = types.StringValue(ngState.Name)
+	state.Count = types.Int64Value(ngState.Count)
+	state.NodeType = types.StringValue(ngState.InstanceType)
 	state.IMIId = types.StringValue(ngState.IMIID)
 	state.State = types.StringValue(ngState.State)
+	state.UserDataURL = optionalString(ngState.UserDataURL)
+	state.SSHPublicKeyNames = nil
+	for _, k := range ngState.SSHKeyNames {
+		state.SSHPublicKeyNames = append(state.SSHPublicKeyNames, types.StringValue(k.Name))
+	}
+	if len(ngState.VNets) > 0 {
+		state.Interfaces = nil
+		for _, v := range ngState.VNets {
+			state.Interfaces = append(state.Interfaces, models.NetworkInterfaceSpec{
+				Name: types.StringValue(v.AvailabilityZone),
+				VNet: types.StringValue(v.VNet),
+			})
+		}
+	}
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, &state)
@@ -202,8 +265,25 @@ func (r *iksNodeGroupResource) Read(ctx context.Context, req resource.ReadReques
 
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update only changes the timeouts, every other configurable attribute of a
+// node group requires its replacement.
 func (r *iksNodeGroupResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
+}
+
+// ImportState imports a node group by an ID of the form
+// <cluster_uuid>/<node group id>.
+func (r *iksNodeGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	clusterUUID, id, ok := strings.Cut(req.ID, "/")
+	if !ok || clusterUUID == "" || id == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: cluster_uuid/id. Got: %q", req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_uuid"), clusterUUID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -216,9 +296,17 @@ func (r *iksNodeGroupResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultIKSNodeGroupTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteIKSNodeGroup(ctx, state.ClusterUUID.ValueString(), state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error Deleting IDC IKS node group resource",
 			"Could not delete IDC KS node group resource ID "+state.ID.ValueString()+": "+err.Error(),
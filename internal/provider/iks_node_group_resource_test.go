@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccIKSNodeGroupResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckIKSNodeGroupDestroy,
+			testAccCheckIKSClusterDestroy,
+			testAccCheckSSHKeyDestroy,
+			testAccCheckVNetDestroy,
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccIKSNodeGroupResourceConfig(name, 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_iks_node_group.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_iks_node_group.test", "node_count", "1"),
+					resource.TestCheckResourceAttr("intelcloud_iks_node_group.test", "state", "Active"),
+					resource.TestCheckResourceAttrPair("intelcloud_iks_node_group.test", "cluster_uuid", "intelcloud_iks_cluster.test", "id"),
+					resource.TestCheckResourceAttrSet("intelcloud_iks_node_group.test", "id"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "intelcloud_iks_node_group.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccImportStateIDFunc("intelcloud_iks_node_group.test", "cluster_uuid", "id"),
+				ImportStateVerify: true,
+			},
+			// Update testing, a node group of another size replaces the old one
+			{
+				Config: testAccIKSNodeGroupResourceConfig(name, 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_node_group.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_iks_node_group.test", "node_count", "2"),
+			},
+			// Drift testing, a node group deleted outside of Terraform is recreated
+			{
+				Config:             testAccIKSNodeGroupResourceConfig(name, 2),
+				Check:              testAccDeleteOutOfBand("intelcloud_iks_node_group.test", deleteIKSNodeGroup),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccIKSNodeGroupResourceConfig(name, 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_iks_node_group.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccIKSNodeGroupResourceConfig(name string, count int) string {
+	return testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + fmt.Sprintf(`
+resource "intelcloud_iks_cluster" "test" {
+  name               = %[1]q
+  kubernetes_version = "1.28"
+}
+
+resource "intelcloud_vnet" "test" {
+  name = %[1]q
+}
+
+resource "intelcloud_iks_node_group" "test" {
+  cluster_uuid         = intelcloud_iks_cluster.test.id
+  name                 = %[1]q
+  node_count           = %[2]d
+  node_type            = %[3]q
+  ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+  interfaces = [{
+    name = intelcloud_vnet.test.availability_zone
+    vnet = intelcloud_vnet.test.name
+  }]
+}
+`, name, count, testAccInstanceType)
+}
+
+func deleteIKSNodeGroup(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteIKSNodeGroup(ctx, attrs["cluster_uuid"], attrs["id"])
+}
+
+var testAccCheckIKSNodeGroupDestroy = testAccCheckDestroyed("intelcloud_iks_node_group", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, _, err := client.GetIKSNodeGroupByID(ctx, attrs["cluster_uuid"], attrs["id"])
+	return err
+})
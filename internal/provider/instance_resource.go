@@ -3,11 +3,17 @@ package provider
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -15,14 +21,17 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &computeInstanceResource{}
-	_ resource.ResourceWithConfigure = &computeInstanceResource{}
+	_ resource.Resource                = &computeInstanceResource{}
+	_ resource.ResourceWithConfigure   = &computeInstanceResource{}
+	_ resource.ResourceWithImportState = &computeInstanceResource{}
+	_ resource.ResourceWithModifyPlan  = &computeInstanceResource{}
 )
 
 // orderFilesystemModel maps the resource schema data.
@@ -36,8 +45,17 @@ type computeInstanceResourceModel struct {
 	Interfaces       types.List           `tfsdk:"interfaces"`
 	SSHProxy         types.Object         `tfsdk:"ssh_proxy"`
 	AccessInfo       types.Object         `tfsdk:"access_info"`
+	PowerState       types.String         `tfsdk:"power_state"`
+	AdoptExisting    types.Bool           `tfsdk:"adopt_existing"`
+	Timeouts         timeouts.Value       `tfsdk:"timeouts"`
 }
 
+// Power states of an instance.
+const (
+	powerStateRunning = "running"
+	powerStateStopped = "stopped"
+)
+
 // NewOrderFilesystem is a helper function to simplify the provider implementation.
 func NewComputeInstanceResource() resource.Resource {
 	return &computeInstanceResource{}
@@ -74,7 +92,7 @@ func (r *computeInstanceResource) Metadata(_ context.Context, req resource.Metad
 }
 
 // Schema defines the schema for the resource.
-func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *computeInstanceResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -82,24 +100,43 @@ func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaReq
 			},
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"cloudaccount": schema.StringAttribute{
 				Computed: true,
 			},
 			"availability_zone": schema.StringAttribute{
+				Optional: true,
 				Computed: true,
+				Description: "The availability zone of the instance. Defaults to the availability zone of the vnets of " +
+					"its interfaces, or to the first zone of the region, such as us-region-1a.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"spec": schema.SingleNestedAttribute{
 				Required: true,
 				Attributes: map[string]schema.Attribute{
 					"instance_group": schema.StringAttribute{
 						Optional: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 					"instance_type": schema.StringAttribute{
 						Required: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 					"machine_image": schema.StringAttribute{
 						Required: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 					"ssh_public_key_names": schema.ListAttribute{
 						ElementType: types.StringType,
@@ -120,6 +157,8 @@ func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaReq
 			"interfaces": schema.ListNestedAttribute{
 				Optional: true,
 				Computed: true,
+				Description: "The network interfaces of the instance, on vnets of its availability zone. Defaults to " +
+					"an eth0 interface on the default vnet of the zone, created if it does not exist.",
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"address": schema.StringAttribute{
@@ -141,9 +180,12 @@ func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaReq
 							},
 						},
 						"name": schema.StringAttribute{
-							Computed: true,
+							Optional:    true,
+							Computed:    true,
+							Description: "The name of the interface. Defaults to eth0 for the first interface, eth1 for the second and so on.",
 							PlanModifiers: []planmodifier.String{
 								stringplanmodifier.UseStateForUnknown(),
+								stringplanmodifier.RequiresReplace(),
 							},
 						},
 						"prefix_length": schema.Int64Attribute{
@@ -159,15 +201,23 @@ func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaReq
 							},
 						},
 						"vnet": schema.StringAttribute{
-							Computed: true,
+							Optional:    true,
+							Computed:    true,
+							Description: "The name of the vnet of the interface. Defaults to the default vnet of the availability zone.",
 							PlanModifiers: []planmodifier.String{
 								stringplanmodifier.UseStateForUnknown(),
+								stringplanmodifier.RequiresReplace(),
 							},
 						},
 					},
 				},
 				PlanModifiers: []planmodifier.List{
 					listplanmodifier.UseStateForUnknown(),
+					// Changed interfaces require a replacement through their
+					// attributes, added or removed ones through the list.
+					listplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.ListRequest, resp *listplanmodifier.RequiresReplaceIfFuncResponse) {
+						resp.RequiresReplace = !req.PlanValue.IsUnknown() && len(req.PlanValue.Elements()) != len(req.StateValue.Elements())
+					}, "Adding or removing interfaces requires a replacement.", "Adding or removing interfaces requires a replacement."),
 				},
 			},
 			"access_info": schema.ObjectAttribute{
@@ -187,6 +237,30 @@ func (r *computeInstanceResource) Schema(_ context.Context, _ resource.SchemaReq
 			"status": schema.StringAttribute{
 				Computed: true,
 			},
+			"power_state": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				Description: "Whether the instance is running or stopped. A stopped instance keeps its disk and network " +
+					"interfaces. Defaults to the power state of the instance.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(powerStateRunning, powerStateStopped),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Optional: true,
+				Description: "Adopt an existing instance with the same name, instance type and machine image " +
+					"instead of creating one. The adopted instance is deleted when the resource is destroyed.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -202,13 +276,17 @@ func (r *computeInstanceResource) Create(ctx context.Context, req resource.Creat
 		return
 	}
 
-	tflog.Info(ctx, "making a call to IDC Service to createVnetIfNotExist")
-	vnetResp, err := r.client.CreateVNetIfNotFound(ctx)
-	if err != nil || vnetResp == nil {
-		resp.Diagnostics.AddError(
-			"Error creating order",
-			"Could not create order, unexpected error: "+err.Error(),
-		)
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultInstanceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	zone, interfaces, diags := r.instanceNetwork(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -224,29 +302,18 @@ func (r *computeInstanceResource) Create(ctx context.Context, req resource.Creat
 			Name: plan.Name.ValueString(),
 		},
 		Spec: struct {
-			AvailabilityZone string "json:\"availabilityZone\""
-			InstanceGroup    string "json:\"instanceGroup,omitempty\""
-			InstanceType     string "json:\"instanceType\""
-			Interfaces       []struct {
-				Name string "json:\"name\""
-				VNet string "json:\"vNet\""
-			} "json:\"interfaces\""
-			MachineImage        string   "json:\"machineImage\""
-			SshPublicKeyNames   []string "json:\"sshPublicKeyNames\""
-			UserData            string   "json:\"userData,omitempty\""
-			QuickConnectEnabled string   "json:\"quickConnectEnabled,omitempty\""
+			AvailabilityZone    string                           "json:\"availabilityZone\""
+			InstanceGroup       string                           "json:\"instanceGroup,omitempty\""
+			InstanceType        string                           "json:\"instanceType\""
+			Interfaces          []itacservices.InstanceInterface "json:\"interfaces\""
+			MachineImage        string                           "json:\"machineImage\""
+			SshPublicKeyNames   []string                         "json:\"sshPublicKeyNames\""
+			UserData            string                           "json:\"userData,omitempty\""
+			QuickConnectEnabled string                           "json:\"quickConnectEnabled,omitempty\""
 		}{
-			AvailabilityZone: fmt.Sprintf("%sa", *r.client.Region),
-			InstanceGroup:    plan.Spec.InstanceGroup.ValueString(),
-			Interfaces: []struct {
-				Name string "json:\"name\""
-				VNet string "json:\"vNet\""
-			}{
-				{
-					Name: "eth0",
-					VNet: fmt.Sprintf("%sa-default", *r.client.Region),
-				},
-			},
+			AvailabilityZone:    zone,
+			InstanceGroup:       plan.Spec.InstanceGroup.ValueString(),
+			Interfaces:          interfaces,
 			InstanceType:        plan.Spec.InstanceType.ValueString(),
 			MachineImage:        plan.Spec.MachineImage.ValueString(),
 			UserData:            plan.Spec.UserData.ValueString(),
@@ -255,70 +322,78 @@ func (r *computeInstanceResource) Create(ctx context.Context, req resource.Creat
 		},
 	}
 
-	tflog.Info(ctx, "making a call to IDC Service for create instance")
-	instResp, err := r.client.CreateInstance(ctx, &inArg, false)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating order",
-			"Could not create order, unexpected error: "+err.Error(),
-		)
-		return
+	var instResp *itacservices.Instance
+	if plan.AdoptExisting.ValueBool() {
+		existing, err := r.client.GetInstanceByName(ctx, inArg.Metadata.Name)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating order",
+				"Could not look up an existing instance named "+inArg.Metadata.Name+": "+err.Error(),
+			)
+			return
+		}
+		if existing != nil && !existing.Matches(&inArg) {
+			resp.Diagnostics.AddAttributeError(path.Root("name"),
+				"Error adopting existing instance",
+				fmt.Sprintf("The instance %s named %q has instance type %s and machine image %s, which do not match the configuration.",
+					existing.Metadata.ResourceId, existing.Metadata.Name, existing.Spec.InstanceType, existing.Spec.MachineImage),
+			)
+			return
+		}
+		if existing != nil {
+			tflog.Info(ctx, "adopting existing instance", map[string]any{"id": existing.Metadata.ResourceId})
+			instResp, err = r.client.WaitForInstancePhase(ctx, existing.Metadata.ResourceId, powerStatePhase(instancePowerState(existing)))
+			if err != nil {
+				addCreateFailure(ctx, req, resp, path.Root("id"), path.Root("status"), "Error creating order", "Could not create order", err)
+				return
+			}
+		}
 	}
 
-	// Map response body to schema and populate Computed attribute values
-	plan.ID = types.StringValue(instResp.Metadata.ResourceId)
-	plan.Cloudaccount = types.StringValue(instResp.Metadata.Cloudaccount)
-	plan.Status = types.StringValue(instResp.Status.Phase)
-	plan.AvailabilityZone = types.StringValue(instResp.Spec.AvailabilityZone)
-
-	accessInfoMap := models.InstanceAccessInfoModel{
-		Username: types.StringValue(instResp.Status.UserName),
+	if instResp == nil {
+		tflog.Info(ctx, "making a call to IDC Service for create instance")
+		var err error
+		instResp, err = r.client.CreateInstance(ctx, &inArg, false)
+		if err != nil {
+			if nameTaken(ctx, err, func(ctx context.Context) (bool, error) {
+				existing, err := r.client.GetInstanceByName(ctx, inArg.Metadata.Name)
+				return existing != nil, err
+			}) {
+				addNameTakenError(&resp.Diagnostics, path.Root("name"), "instance", inArg.Metadata.Name, err)
+				return
+			}
+			addCreateFailure(ctx, req, resp, path.Root("id"), path.Root("status"), "Error creating order", "Could not create order", err)
+			return
+		}
 	}
 
-	plan.AccessInfo, diags = types.ObjectValueFrom(ctx, accessInfoMap.AttributeTypes(), accessInfoMap)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	// Stop the instance, or start an adopted one, as configured.
+	var powerErr error
+	if want := plan.PowerState.ValueString(); want != "" && want != instancePowerState(instResp) {
+		update := instanceUpdateRequest(plan.Spec)
+		update.Spec.RunStrategy = powerStateRunStrategy(want)
+		var instance *itacservices.Instance
+		if instance, powerErr = r.applyUpdate(ctx, instResp.Metadata.ResourceId, update, want); powerErr == nil {
+			instResp = instance
+		}
 	}
 
-	sshProxyMap := models.SSHProxyModel{
-		ProxyAddress: types.StringValue(instResp.Status.SSHProxy.Address),
-		ProxyPort:    types.Int64Value(instResp.Status.SSHProxy.Port),
-		ProxyUser:    types.StringValue(instResp.Status.SSHProxy.User),
-	}
-	plan.SSHProxy, diags = types.ObjectValueFrom(ctx, sshProxyMap.AttributeTypes(), sshProxyMap)
-	resp.Diagnostics.Append(diags...)
+	// Map response body to schema and populate Computed attribute values
+	resp.Diagnostics.Append(r.setComputedAttributes(ctx, &plan, instResp)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	infs := []models.NetworkInterface{}
-	for _, nic := range instResp.Status.Interfaces {
-		// currently we ssume a single interface will have a single address
-		addr := ""
-		if len(nic.Addresses) > 0 {
-			addr = nic.Addresses[0]
-		}
-		inf := models.NetworkInterface{
-			Addresses:    types.StringValue(addr),
-			DNSName:      types.StringValue(nic.DNSName),
-			Gateway:      types.StringValue(nic.Gateway),
-			Name:         types.StringValue(nic.Name),
-			PrefixLength: types.Int64Value(int64(nic.PrefixLength)),
-			Subnet:       types.StringValue(nic.Subnet),
-			VNet:         types.StringValue(nic.VNet),
-		}
-		infs = append(infs, inf)
-	}
-	plan.Interfaces, diags = types.ListValueFrom(ctx, types.ObjectType{}.WithAttributeTypes(models.ProviderInterfaceAttributes), infs)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	if powerErr != nil {
+		// The instance is saved in the power state it is in, tainted.
+		plan.PowerState = types.StringValue(instancePowerState(instResp))
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		resp.Diagnostics.AddError(
+			"Error setting instance power state",
+			"Could not set the power state of instance "+instResp.Metadata.ResourceId+", unexpected error: "+powerErr.Error(),
+		)
 		return
 	}
 
-	// Set quick connect URL if required
-	plan.Spec.QuickConnectUrl = types.StringValue(r.getQuickConnectUrl(plan.Spec.QuickConnectEnabled, instResp))
-
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -330,9 +405,25 @@ func (r *computeInstanceResource) Create(ctx context.Context, req resource.Creat
 
 // Read refreshes the Terraform state with the latest data.
 func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
+	// Get current state. Only the id is known after an import.
 	var state computeInstanceResourceModel
-	diags := req.State.Get(ctx, &state)
+	diags := req.State.GetAttribute(ctx, path.Root("id"), &state.ID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.GetAttribute(ctx, path.Root("timeouts"), &state.Timeouts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.GetAttribute(ctx, path.Root("adopt_existing"), &state.AdoptExisting)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var quickConnectEnabled types.String
+	diags = req.State.GetAttribute(ctx, path.Root("spec").AtName("quick_connect_enabled"), &quickConnectEnabled)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -341,6 +432,11 @@ func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadReq
 	// Get refreshed order value from IDC Service
 	instance, err := r.client.GetInstanceByResourceId(ctx, state.ID.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "instance not found, removing it from state", map[string]any{"id": state.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC Compute Instance resource",
 			"Could not read IDC Compute Instance resource ID "+state.ID.ValueString()+": "+err.Error(),
@@ -348,29 +444,33 @@ func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadReq
 		return
 	}
 
-	tflog.Debug(ctx, "instance read request response", map[string]any{"instance": instance, "resourceId": state.ID.ValueString()})
-
-	// state = orderInstanceModel{}
-	// state.Instance = models.InstanceModel{}
+	tflog.Debug(ctx, "instance read request response", map[string]any{"phase": instance.Status.Phase, "resourceId": state.ID.ValueString()})
 
 	state.Cloudaccount = types.StringValue(instance.Metadata.Cloudaccount)
 	state.ID = types.StringValue(instance.Metadata.ResourceId)
 	state.Name = types.StringValue(instance.Metadata.Name)
 	state.AvailabilityZone = types.StringValue(instance.Spec.AvailabilityZone)
 	state.Spec = &models.InstanceSpec{
-		InstanceGroup:       types.StringValue(instance.Spec.InstanceGroup),
+		InstanceGroup:       optionalString(instance.Spec.InstanceGroup),
 		InstanceType:        types.StringValue(instance.Spec.InstanceType),
 		MachineImage:        types.StringValue(instance.Spec.MachineImage),
-		UserData:            types.StringValue(instance.Spec.UserData),
-		QuickConnectEnabled: types.StringValue(instance.Spec.QuickConnectEnabled),
-		QuickConnectUrl:     types.StringValue(instance.Spec.QuickConnectUrl),
+		UserData:            optionalString(instance.Spec.UserData),
+		QuickConnectEnabled: optionalString(instance.Spec.QuickConnectEnabled),
 	}
+	// The API capitalizes quick_connect_enabled, keep the configured spelling,
+	// or null when the setting was removed and turned quick connect off.
+	if strings.EqualFold(quickConnectEnabled.ValueString(), instance.Spec.QuickConnectEnabled) ||
+		quickConnectEnabled.IsNull() && instance.Spec.QuickConnectEnabled == "False" {
+		state.Spec.QuickConnectEnabled = quickConnectEnabled
+	}
+	state.Spec.QuickConnectUrl = types.StringValue(r.getQuickConnectUrl(state.Spec.QuickConnectEnabled, instance))
 
 	for _, k := range instance.Spec.SshPublicKeyNames {
 		state.Spec.SSHPublicKeyNames = append(state.Spec.SSHPublicKeyNames, types.StringValue(k))
 	}
 
 	state.Status = types.StringValue(instance.Status.Phase)
+	state.PowerState = types.StringValue(instancePowerState(instance))
 
 	accessInfoMap := models.InstanceAccessInfoModel{
 		Username: types.StringValue(instance.Status.UserName),
@@ -395,8 +495,12 @@ func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadReq
 
 	infs := []models.NetworkInterface{}
 	for _, nic := range instance.Status.Interfaces {
+		addr := ""
+		if len(nic.Addresses) > 0 {
+			addr = nic.Addresses[0]
+		}
 		inf := models.NetworkInterface{
-			Addresses:    types.StringValue(nic.Addresses[0]),
+			Addresses:    types.StringValue(addr),
 			DNSName:      types.StringValue(nic.DNSName),
 			Gateway:      types.StringValue(nic.Gateway),
 			Name:         types.StringValue(nic.Name),
@@ -404,9 +508,6 @@ func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadReq
 			Subnet:       types.StringValue(nic.Subnet),
 			VNet:         types.StringValue(nic.VNet),
 		}
-		// for _, addr := range nic.Addresses {
-		// 	inf.Addresses = append(inf.Addresses, types.StringValue(addr))
-		// }
 		infs = append(infs, inf)
 	}
 	state.Interfaces, diags = types.ListValueFrom(ctx, types.ObjectType{}.WithAttributeTypes(models.ProviderInterfaceAttributes), infs)
@@ -425,8 +526,259 @@ func (r *computeInstanceResource) Read(ctx context.Context, req resource.ReadReq
 	}
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// ModifyPlan plans the update resuming an interrupted create, which sets the
+// attributes known once the instance is ready.
+func (r *computeInstanceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	paths := []path.Path{
+		path.Root("availability_zone"),
+		path.Root("status"),
+		path.Root("power_state"),
+		path.Root("interfaces"),
+		path.Root("access_info"),
+		path.Root("ssh_proxy"),
+		path.Root("spec").AtName("quick_connect_url"),
+	}
+	// Only the attributes the API sets are unknown in configured interfaces.
+	var interfaces types.List
+	if !req.Plan.Raw.IsNull() {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("interfaces"), &interfaces)...)
+	}
+	for i := range interfaces.Elements() {
+		for _, name := range []string{"address", "dns_name", "gateway", "prefix_length", "subnet"} {
+			paths = append(paths, path.Root("interfaces").AtListIndex(i).AtName(name))
+		}
+	}
+	planResumedCreate(ctx, req, resp, paths...)
+}
+
+// Update resumes an interrupted create and changes the ssh keys, user data,
+// quick connect setting and power state of the instance in place. Every
+// other attribute of the spec requires its replacement.
 func (r *computeInstanceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state computeInstanceResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resuming := resumingCreate(ctx, req.Private, &resp.Diagnostics)
+	update := instanceUpdate(&plan, &state)
+	if !resuming && update == nil {
+		updateTimeouts(ctx, req, resp)
+		updateAdoptExisting(ctx, req, resp)
+		return
+	}
+
+	timeout, diags := plan.Timeouts.Update(ctx, itacservices.DefaultInstanceTimeout)
+	if resuming {
+		timeout, diags = plan.Timeouts.Create(ctx, itacservices.DefaultInstanceTimeout)
+	}
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	id := state.ID.ValueString()
+	if resuming {
+		tflog.Info(ctx, "resuming the create of instance", map[string]any{"id": id})
+		if _, err := r.client.WaitForInstance(ctx, id); err != nil {
+			addCreateError(&resp.Diagnostics, "Error creating order", "Could not create order", err)
+			return
+		}
+	}
+
+	// The power state is unknown when resuming the create of an instance
+	// that does not configure it.
+	powerState := plan.PowerState.ValueString()
+	if plan.PowerState.IsUnknown() {
+		powerState = powerStateRunning
+	}
+	instance, err := r.applyUpdate(ctx, id, update, powerState)
+	if err != nil {
+		addCreateError(&resp.Diagnostics, "Error updating instance", "Could not update instance "+id, err)
+		return
+	}
+
+	resp.Diagnostics.Append(r.setComputedAttributes(ctx, &plan, instance)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if resuming {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateCreating, nil)...)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// applyUpdate sends update, if any, to the instance and waits for it to be
+// ready, or stopped when powerState is stopped.
+func (r *computeInstanceResource) applyUpdate(ctx context.Context, id string, update *itacservices.InstanceUpdateRequest, powerState string) (*itacservices.Instance, error) {
+	if update != nil {
+		tflog.Info(ctx, "making a call to IDC Service for update instance", map[string]any{"id": id, "runStrategy": update.Spec.RunStrategy})
+		if err := r.client.UpdateInstance(ctx, id, update); err != nil {
+			return nil, err
+		}
+	}
+	return r.client.WaitForInstancePhase(ctx, id, powerStatePhase(powerState))
+}
+
+// instanceUpdate returns the request changing the instance from state to
+// plan, nil if nothing changes in place.
+func instanceUpdate(plan, state *computeInstanceResourceModel) *itacservices.InstanceUpdateRequest {
+	sameKeys := slices.EqualFunc(plan.Spec.SSHPublicKeyNames, state.Spec.SSHPublicKeyNames, func(a, b types.String) bool {
+		return a.Equal(b)
+	})
+	samePowerState := plan.PowerState.IsUnknown() || plan.PowerState.Equal(state.PowerState)
+	if sameKeys && samePowerState &&
+		plan.Spec.UserData.Equal(state.Spec.UserData) &&
+		plan.Spec.QuickConnectEnabled.Equal(state.Spec.QuickConnectEnabled) {
+		return nil
+	}
+
+	update := instanceUpdateRequest(plan.Spec)
+	// Removing the setting turns quick connect off.
+	if plan.Spec.QuickConnectEnabled.IsNull() && !state.Spec.QuickConnectEnabled.IsNull() {
+		update.Spec.QuickConnectEnabled = "False"
+	}
+	if !samePowerState {
+		update.Spec.RunStrategy = powerStateRunStrategy(plan.PowerState.ValueString())
+	}
+	return update
+}
+
+// instanceUpdateRequest returns the request setting the attributes of the
+// instance that change in place to those of spec.
+func instanceUpdateRequest(spec *models.InstanceSpec) *itacservices.InstanceUpdateRequest {
+	update := &itacservices.InstanceUpdateRequest{}
+	for _, k := range spec.SSHPublicKeyNames {
+		update.Spec.SshPublicKeyNames = append(update.Spec.SshPublicKeyNames, k.ValueString())
+	}
+	update.Spec.UserData = spec.UserData.ValueString()
+	update.Spec.QuickConnectEnabled = capitalize(spec.QuickConnectEnabled.ValueString())
+	return update
+}
+
+// instanceNetwork returns the availability zone and interfaces of the
+// instance planned. The vnets of configured interfaces must exist and be in
+// the zone, which defaults to theirs, or to the first zone of the region.
+// Interfaces without a vnet, or the eth0 interface of an instance without
+// any, are on the default vnet of the zone, created if it does not exist.
+func (r *computeInstanceResource) instanceNetwork(ctx context.Context, plan *computeInstanceResourceModel) (string, []itacservices.InstanceInterface, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	zone := plan.AvailabilityZone.ValueString()
+
+	nics := []models.NetworkInterface{{}}
+	if !plan.Interfaces.IsUnknown() && !plan.Interfaces.IsNull() {
+		diags.Append(plan.Interfaces.ElementsAs(ctx, &nics, false)...)
+		if diags.HasError() {
+			return "", nil, diags
+		}
+	}
+
+	var vnets *itacservices.VNets
+	interfaces := make([]itacservices.InstanceInterface, len(nics))
+	for i, nic := range nics {
+		interfaces[i].Name = nic.Name.ValueString()
+		if interfaces[i].Name == "" {
+			interfaces[i].Name = fmt.Sprintf("eth%d", i)
+		}
+		name := nic.VNet.ValueString()
+		if name == "" {
+			continue
+		}
+		if vnets == nil {
+			var err error
+			if vnets, err = r.client.GetVNets(ctx); err != nil {
+				diags.AddError(
+					"Error creating order",
+					"Could not read the vnets of the interfaces, unexpected error: "+err.Error(),
+				)
+				return "", nil, diags
+			}
+		}
+
+		vnetPath := path.Root("interfaces").AtListIndex(i).AtName("vnet")
+		idx := slices.IndexFunc(vnets.Vnets, func(vnet itacservices.VNet) bool {
+			return vnet.Metadata.Name == name
+		})
+		if idx < 0 {
+			diags.AddAttributeError(vnetPath, "Invalid vnet", fmt.Sprintf("The vnet %q does not exist.", name))
+			continue
+		}
+		vnetZone := vnets.Vnets[idx].Spec.AvailabilityZone
+		if zone == "" {
+			zone = vnetZone
+		}
+		if vnetZone != zone {
+			diags.AddAttributeError(vnetPath, "Invalid vnet",
+				fmt.Sprintf("The vnet %q is in availability zone %s, the instance is in %s. "+
+					"The vnets of an instance must be in its availability zone.", name, vnetZone, zone))
+			continue
+		}
+		interfaces[i].VNet = name
+	}
+	if diags.HasError() {
+		return "", nil, diags
+	}
+
+	if zone == "" {
+		zone = r.client.DefaultAvailabilityZone()
+	}
+	var defaultVNet *itacservices.VNet
+	for i := range interfaces {
+		if interfaces[i].VNet != "" {
+			continue
+		}
+		if defaultVNet == nil {
+			tflog.Info(ctx, "making a call to IDC Service to createVnetIfNotExist", map[string]any{"availabilityZone": zone})
+			var err error
+			if defaultVNet, err = r.client.CreateVNetIfNotFound(ctx, zone); err != nil {
+				diags.AddError(
+					"Error creating order",
+					"Could not create the default vnet of availability zone "+zone+", unexpected error: "+err.Error(),
+				)
+				return "", nil, diags
+			}
+		}
+		interfaces[i].VNet = defaultVNet.Metadata.Name
+	}
+	return zone, interfaces, diags
+}
+
+// instancePowerState returns the power state of the instance, stopped from
+// the time it is being stopped.
+func instancePowerState(instance *itacservices.Instance) string {
+	if instance.Spec.RunStrategy == itacservices.RunStrategyHalted {
+		return powerStateStopped
+	}
+	switch instance.Status.Phase {
+	case "Stopping", itacservices.InstancePhaseStopped:
+		return powerStateStopped
+	}
+	return powerStateRunning
+}
+
+// powerStateRunStrategy returns the run strategy giving the power state.
+func powerStateRunStrategy(powerState string) string {
+	if powerState == powerStateStopped {
+		return itacservices.RunStrategyHalted
+	}
+	return itacservices.RunStrategyAlways
+}
+
+// powerStatePhase returns the phase of an instance in the power state.
+func powerStatePhase(powerState string) string {
+	if powerState == powerStateStopped {
+		return itacservices.InstancePhaseStopped
+	}
+	return itacservices.InstancePhaseReady
 }
 
 func (r *computeInstanceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -444,17 +796,83 @@ func (r *computeInstanceResource) Delete(ctx context.Context, req resource.Delet
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultInstanceTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteInstanceByResourceId(ctx, state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
-			"Error Deleting IDC Filesystem resource",
-			"Could not delete IDC Filesystem resource ID "+state.ID.ValueString()+": "+err.Error(),
+			"Error Deleting IDC Compute Instance resource",
+			"Could not delete IDC Compute Instance resource ID "+state.ID.ValueString()+": "+err.Error(),
 		)
 		return
 	}
 }
 
+// setComputedAttributes sets the attributes of model computed by the API
+// from instance.
+func (r *computeInstanceResource) setComputedAttributes(ctx context.Context, model *computeInstanceResourceModel, instance *itacservices.Instance) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	model.ID = types.StringValue(instance.Metadata.ResourceId)
+	model.Cloudaccount = types.StringValue(instance.Metadata.Cloudaccount)
+	model.Status = types.StringValue(instance.Status.Phase)
+	model.PowerState = types.StringValue(instancePowerState(instance))
+	model.AvailabilityZone = types.StringValue(instance.Spec.AvailabilityZone)
+
+	accessInfoMap := models.InstanceAccessInfoModel{
+		Username: types.StringValue(instance.Status.UserName),
+	}
+
+	model.AccessInfo, diags = types.ObjectValueFrom(ctx, accessInfoMap.AttributeTypes(), accessInfoMap)
+	if diags.HasError() {
+		return diags
+	}
+
+	sshProxyMap := models.SSHProxyModel{
+		ProxyAddress: types.StringValue(instance.Status.SSHProxy.Address),
+		ProxyPort:    types.Int64Value(instance.Status.SSHProxy.Port),
+		ProxyUser:    types.StringValue(instance.Status.SSHProxy.User),
+	}
+	model.SSHProxy, diags = types.ObjectValueFrom(ctx, sshProxyMap.AttributeTypes(), sshProxyMap)
+	if diags.HasError() {
+		return diags
+	}
+
+	infs := []models.NetworkInterface{}
+	for _, nic := range instance.Status.Interfaces {
+		// currently we ssume a single interface will have a single address
+		addr := ""
+		if len(nic.Addresses) > 0 {
+			addr = nic.Addresses[0]
+		}
+		inf := models.NetworkInterface{
+			Addresses:    types.StringValue(addr),
+			DNSName:      types.StringValue(nic.DNSName),
+			Gateway:      types.StringValue(nic.Gateway),
+			Name:         types.StringValue(nic.Name),
+			PrefixLength: types.Int64Value(int64(nic.PrefixLength)),
+			Subnet:       types.StringValue(nic.Subnet),
+			VNet:         types.StringValue(nic.VNet),
+		}
+		infs = append(infs, inf)
+	}
+	model.Interfaces, diags = types.ListValueFrom(ctx, types.ObjectType{}.WithAttributeTypes(models.ProviderInterfaceAttributes), infs)
+	if diags.HasError() {
+		return diags
+	}
+
+	// Set quick connect URL if required
+	model.Spec.QuickConnectUrl = types.StringValue(r.getQuickConnectUrl(model.Spec.QuickConnectEnabled, instance))
+	return diags
+}
+
 func (r *computeInstanceResource) getQuickConnectUrl(quickConnectEnabled types.String, inst *itacservices.Instance) string {
 	if capitalize(quickConnectEnabled.ValueString()) == "True" {
 		return fmt.Sprintf("https://%s.connect.%s.devcloudtenant.io/v1/connect/%s/%s",
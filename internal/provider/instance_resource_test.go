@@ -0,0 +1,599 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/itacfake"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccInstanceResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckInstanceDestroy,
+			testAccCheckSSHKeyDestroy,
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccInstanceResourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "spec.instance_type", testAccInstanceType),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "spec.machine_image", testAccMachineImage),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", "Ready"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.#", "1"),
+					resource.TestCheckResourceAttrSet("intelcloud_instance.test", "interfaces.0.address"),
+					resource.TestCheckResourceAttrSet("intelcloud_instance.test", "id"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "timeouts.create", "20m"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "intelcloud_instance.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+			// Drift testing, an instance deleted outside of Terraform is recreated
+			{
+				Config:             testAccInstanceResourceConfig(name),
+				Check:              testAccDeleteOutOfBand("intelcloud_instance.test", deleteInstance),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccInstanceResourceConfig(name),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccInstanceResource_update(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckInstanceDestroy,
+			testAccCheckSSHKeyDestroy,
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceResourceConfigSpec(name, testAccInstanceType, `
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+    user_data            = "#cloud-config\n"
+`),
+				Check: resource.TestCheckResourceAttrWith("intelcloud_instance.test", "id", func(value string) error {
+					id = value
+					return nil
+				}),
+			},
+			// ssh keys, user data and quick connect change in place
+			{
+				Config: testAccInstanceResourceConfigSpec(name, testAccInstanceType, `
+    ssh_public_key_names  = [intelcloud_sshkey.test.metadata.name, intelcloud_sshkey.other.metadata.name]
+    user_data             = "#cloud-config\npackages: [jq]\n"
+    quick_connect_enabled = "true"
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("intelcloud_instance.test", "id", func(value string) error {
+						if value != id {
+							return fmt.Errorf("instance %s replaced by %s", id, value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "spec.ssh_public_key_names.#", "2"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "spec.user_data", "#cloud-config\npackages: [jq]\n"),
+					resource.TestCheckResourceAttrSet("intelcloud_instance.test", "spec.quick_connect_url"),
+					testAccCheckInstanceSpec("intelcloud_instance.test", func(instance *itacservices.Instance) error {
+						if len(instance.Spec.SshPublicKeyNames) != 2 {
+							return fmt.Errorf("instance has ssh keys %v, expected 2", instance.Spec.SshPublicKeyNames)
+						}
+						return nil
+					}),
+				),
+			},
+			// Removing quick_connect_enabled turns quick connect off
+			{
+				Config: testAccInstanceResourceConfigSpec(name, testAccInstanceType, `
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("intelcloud_instance.test", "spec.quick_connect_enabled"),
+					resource.TestCheckNoResourceAttr("intelcloud_instance.test", "spec.user_data"),
+				),
+			},
+			// The instance type cannot change in place
+			{
+				Config: testAccInstanceResourceConfigSpec(name, testAccOtherInstanceType, `
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_instance.test", "spec.instance_type", testAccOtherInstanceType),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_powerState(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	running := testAccInstanceResourceConfigPowerState(name, "running")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckInstanceDestroy,
+			testAccCheckSSHKeyDestroy,
+		),
+		Steps: []resource.TestStep{
+			// Created stopped
+			{
+				Config: testAccInstanceResourceConfigPowerState(name, "stopped"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "power_state", "stopped"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", itacservices.InstancePhaseStopped),
+				),
+			},
+			// Started in place
+			{
+				Config: running,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "power_state", "running"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", itacservices.InstancePhaseReady),
+				),
+			},
+			// Stopped behind Terraform's back, which the refresh reports
+			{
+				Config:             running,
+				Check:              testAccDeleteOutOfBand("intelcloud_instance.test", stopInstance),
+				ExpectNonEmptyPlan: true,
+			},
+			// and the next apply starts it again
+			{
+				Config: running,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "power_state", "running"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", itacservices.InstancePhaseReady),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_network(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	region := os.Getenv("ITAC_REGION")
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckInstanceDestroy,
+			testAccCheckSSHKeyDestroy,
+			testAccCheckVNetDestroy,
+		),
+		Steps: []resource.TestStep{
+			// The vnets of an instance must be in its zone
+			{
+				Config: testAccInstanceResourceConfigNetwork(name, `
+  availability_zone = intelcloud_vnet.a.availability_zone
+  interfaces        = [{ vnet = intelcloud_vnet.b.name }]
+`),
+				ExpectError: regexp.MustCompile(`must\s+be\s+in\s+its\s+availability\s+zone`),
+			},
+			// The zone defaults to that of the vnets
+			{
+				Config: testAccInstanceResourceConfigNetwork(name, `
+  interfaces = [{ vnet = intelcloud_vnet.a.name }]
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "availability_zone", region+"a"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.#", "1"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.0.name", "eth0"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.0.vnet", name+"-a"),
+					resource.TestCheckResourceAttrSet("intelcloud_instance.test", "interfaces.0.address"),
+					resource.TestCheckResourceAttrWith("intelcloud_instance.test", "id", func(value string) error {
+						id = value
+						return nil
+					}),
+				),
+			},
+			// Setting the defaults changes nothing
+			{
+				Config: testAccInstanceResourceConfigNetwork(name, `
+  availability_zone = intelcloud_vnet.a.availability_zone
+  interfaces        = [{ name = "eth0", vnet = intelcloud_vnet.a.name }]
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// Another vnet replaces the instance
+			{
+				Config: testAccInstanceResourceConfigNetwork(name, `
+  interfaces = [{ vnet = intelcloud_vnet.b.name }]
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "availability_zone", region+"b"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.0.vnet", name+"-b"),
+					testAccCheckInstanceSpec("intelcloud_instance.test", func(instance *itacservices.Instance) error {
+						if instance.Metadata.ResourceId == id {
+							return fmt.Errorf("instance %s not replaced", id)
+						}
+						if len(instance.Spec.Interfaces) != 1 || instance.Spec.Interfaces[0].VNet != name+"-b" {
+							return fmt.Errorf("instance has interfaces %v, expected one on %s-b", instance.Spec.Interfaces, name)
+						}
+						return nil
+					}),
+				),
+			},
+			// and so does a second interface
+			{
+				Config: testAccInstanceResourceConfigNetwork(name, `
+  interfaces = [{ vnet = intelcloud_vnet.b.name }, { vnet = intelcloud_vnet.b.name }]
+`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.#", "2"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "interfaces.1.name", "eth1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_provisioningFailure(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	// Terraform wraps long diagnostics, so words may be split by newlines.
+	message := strings.ReplaceAll(regexp.QuoteMeta(itacfake.FailureMessage), " ", `\s+`)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+			testAccFakeAPI.SetPhaseBehavior(itacfake.KindInstance, name, itacfake.PhaseFail)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccInstanceResourceConfig(name),
+				ExpectError: regexp.MustCompile(`Last phase: Failed\s+Message:\s+` + message),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_createTimeout(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+			testAccFakeAPI.SetPhaseBehavior(itacfake.KindInstance, name, itacfake.PhaseStuck)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			// The instance never becomes ready, it is saved tainted
+			{
+				Config:      testAccInstanceResourceConfigWithCreateTimeout(name, "1s"),
+				ExpectError: regexp.MustCompile(`Last phase: Provisioning`),
+			},
+			// The tainted instance is replaced rather than created twice
+			{
+				PreConfig: func() {
+					testAccFakeAPI.SetPhaseBehavior(itacfake.KindInstance, name, itacfake.PhaseNormal)
+				},
+				Config: testAccInstanceResourceConfig(name),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", "Ready"),
+					testAccCheckInstanceCount(name, 1),
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_interruptedCreate(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	const instancesPath = "/v1/cloudaccounts/*/instances"
+	var posts int
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+			posts = testAccFakeAPI.RequestCount(http.MethodPost, instancesPath)
+			testAccFakeAPI.SetPhaseBehavior(itacfake.KindInstance, name, itacfake.PhaseStuck)
+		},
+		// The create is interrupted while waiting for the instance.
+		ProtoV6ProviderFactories: testAccInterruptingProviderFactories("intelcloud_instance", http.MethodGet, instancesPath+"/id/*"),
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			// The instance being created is saved to resume waiting for it
+			{
+				Config: testAccInstanceResourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("intelcloud_instance.test", "id"),
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", "Provisioning"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+			// The next apply updates it once ready instead of creating another
+			{
+				PreConfig: func() {
+					testAccFakeAPI.SetPhaseBehavior(itacfake.KindInstance, name, itacfake.PhaseNormal)
+				},
+				Config: testAccInstanceResourceConfig(name),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_instance.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", "Ready"),
+					testAccCheckInstanceCount(name, 1),
+					func(*terraform.State) error {
+						if n := testAccFakeAPI.RequestCount(http.MethodPost, instancesPath) - posts; n != 1 {
+							return fmt.Errorf("instance created with %d requests, expected 1", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccInstanceResource_lostCreateResponse(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	const instancesPath = "/v1/cloudaccounts/*/instances"
+	var posts int
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheckFake(t)
+			posts = testAccFakeAPI.RequestCount(http.MethodPost, instancesPath)
+			// The first create reaches the API but its answer is lost.
+			testAccFakeAPI.InjectFault(itacfake.Fault{
+				Method:       http.MethodPost,
+				Path:         instancesPath,
+				Status:       http.StatusGatewayTimeout,
+				LoseResponse: true,
+				Times:        1,
+			})
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckInstanceDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccInstanceResourceConfig(name),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_instance.test", "status", "Ready"),
+					testAccCheckInstanceCount(name, 1),
+					func(*terraform.State) error {
+						if n := testAccFakeAPI.RequestCount(http.MethodPost, instancesPath) - posts; n != 1 {
+							return fmt.Errorf("instance created with %d requests, expected 1", n)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccInstanceResourceConfig(name string) string {
+	return testAccInstanceResourceConfigWithCreateTimeout(name, "20m")
+}
+
+func testAccInstanceResourceConfigWithCreateTimeout(name, timeout string) string {
+	return testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + fmt.Sprintf(`
+resource "intelcloud_instance" "test" {
+  name = %[1]q
+  spec = {
+    instance_type        = %[2]q
+    machine_image        = %[3]q
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+  }
+  timeouts {
+    create = %[4]q
+  }
+}
+`, name, testAccInstanceType, testAccMachineImage, timeout)
+}
+
+func testAccInstanceResourceConfigPowerState(name, powerState string) string {
+	return testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + fmt.Sprintf(`
+resource "intelcloud_instance" "test" {
+  name = %[1]q
+  spec = {
+    instance_type        = %[2]q
+    machine_image        = %[3]q
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+  }
+  power_state = %[4]q
+}
+`, name, testAccInstanceType, testAccMachineImage, powerState)
+}
+
+// testAccInstanceResourceConfigNetwork returns the configuration of an
+// instance with the given network attributes, along with two vnets it can
+// use in zones a and b of the region.
+func testAccInstanceResourceConfigNetwork(name, network string) string {
+	return testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + fmt.Sprintf(`
+resource "intelcloud_vnet" "a" {
+  name              = "%[1]s-a"
+  availability_zone = "%[4]sa"
+}
+
+resource "intelcloud_vnet" "b" {
+  name              = "%[1]s-b"
+  availability_zone = "%[4]sb"
+}
+
+resource "intelcloud_instance" "test" {
+  name = %[1]q
+  spec = {
+    instance_type        = %[2]q
+    machine_image        = %[3]q
+    ssh_public_key_names = [intelcloud_sshkey.test.metadata.name]
+  }
+%[5]s}
+`, name, testAccInstanceType, testAccMachineImage, os.Getenv("ITAC_REGION"), network)
+}
+
+// testAccInstanceResourceConfigSpec returns the configuration of an instance
+// of type instanceType with the given ssh keys and optional attributes of
+// its spec, along with two ssh keys it can use.
+func testAccInstanceResourceConfigSpec(name, instanceType, spec string) string {
+	return testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + fmt.Sprintf(`
+resource "intelcloud_sshkey" "other" {
+  metadata = {
+    name = "%[1]s-other"
+  }
+  spec = {
+    ssh_public_key = %[4]q
+    owner_email    = "acctest@example.com"
+  }
+}
+
+resource "intelcloud_instance" "test" {
+  name = %[1]q
+  spec = {
+    instance_type = %[2]q
+    machine_image = %[3]q
+%[5]s  }
+}
+`, name, instanceType, testAccMachineImage, testAccOtherSSHPublicKey, spec)
+}
+
+// testAccCheckInstanceSpec checks the instance at address as the API reports
+// it.
+func testAccCheckInstanceSpec(address string, check func(instance *itacservices.Instance) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("%s not found in state", address)
+		}
+		client, err := testAccAPIClient()
+		if err != nil {
+			return err
+		}
+		instance, err := client.GetInstanceByResourceId(context.Background(), rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		return check(instance)
+	}
+}
+
+// testAccCheckInstanceCount checks that count instances named name exist.
+func testAccCheckInstanceCount(name string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := testAccAPIClient()
+		if err != nil {
+			return err
+		}
+		instances, err := client.GetInstances(context.Background())
+		if err != nil {
+			return err
+		}
+		n := 0
+		for _, instance := range instances.Instances {
+			if instance.Metadata.Name == name {
+				n++
+			}
+		}
+		if n != count {
+			return fmt.Errorf("found %d instances named %s, expected %d", n, name, count)
+		}
+		return nil
+	}
+}
+
+func deleteInstance(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteInstanceByResourceId(ctx, attrs["id"])
+}
+
+var testAccCheckInstanceDestroy = testAccCheckDestroyed("intelcloud_instance", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetInstanceByResourceId(ctx, attrs["id"])
+	return err
+})
+
+// stopInstance stops the instance through the API.
+func stopInstance(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	instance, err := client.GetInstanceByResourceId(ctx, attrs["id"])
+	if err != nil {
+		return err
+	}
+	update := &itacservices.InstanceUpdateRequest{}
+	update.Spec.SshPublicKeyNames = instance.Spec.SshPublicKeyNames
+	update.Spec.UserData = instance.Spec.UserData
+	update.Spec.QuickConnectEnabled = instance.Spec.QuickConnectEnabled
+	update.Spec.RunStrategy = itacservices.RunStrategyHalted
+	return client.UpdateInstance(ctx, attrs["id"], update)
+}
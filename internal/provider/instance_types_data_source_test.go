@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInstanceTypesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `data "intelcloud_instance_types" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.intelcloud_instance_types.test", "instance_types.#"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.intelcloud_instance_types.test", "instance_types.*", map[string]string{
+						"name": testAccInstanceType,
+					}),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccKubeconfigDataSource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "intelcloud_iks_cluster" "test" {
+  name               = %[1]q
+  kubernetes_version = "1.28"
+}
+
+data "intelcloud_kubeconfig" "test" {
+  cluster_uuid = intelcloud_iks_cluster.test.id
+}
+`, name),
+				Check: resource.TestMatchResourceAttr("data.intelcloud_kubeconfig.test", "kubeconfig", regexp.MustCompile("kind: Config")),
+			},
+		},
+	})
+}
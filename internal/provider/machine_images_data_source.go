@@ -159,11 +159,18 @@ func (d *machineImagesDataSource) Read(ctx context.Context, req datasource.ReadR
 			tfImg.InstanceCategory = append(tfImg.InstanceCategory, types.StringValue(i))
 		}
 		for _, t := range img.Spec.InstanceTypes {
-			tfImg.InstanceTypes = append(tfImg.InstanceCategory, types.StringValue(t))
+			tfImg.InstanceTypes = append(tfImg.InstanceTypes, types.StringValue(t))
 		}
 		allImages = append(allImages, tfImg)
 	}
 	filteredImages := filterImages(allImages, state.Filters)
+	if len(filteredImages) == 0 {
+		resp.Diagnostics.AddError(
+			"No ITAC Machine Image Found",
+			"No machine image matches the given filters.",
+		)
+		return
+	}
 
 	state.Images = append(state.Images, filteredImages...)
 	state.Result = &filteredImages[0]
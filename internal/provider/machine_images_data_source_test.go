@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccMachineImagesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccMachineImagesDataSourceConfig(testAccMachineImage),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.intelcloud_machine_images.test", "items.#", "1"),
+					resource.TestCheckResourceAttr("data.intelcloud_machine_images.test", "result.name", testAccMachineImage),
+				),
+			},
+			{
+				Config:      testAccMachineImagesDataSourceConfig("no-such-image"),
+				ExpectError: regexp.MustCompile("No ITAC Machine Image Found"),
+			},
+		},
+	})
+}
+
+func testAccMachineImagesDataSourceConfig(name string) string {
+	return fmt.Sprintf(`
+data "intelcloud_machine_images" "test" {
+  most_recent = true
+  filters = [{
+    name   = "name"
+    values = [%[1]q]
+  }]
+}
+`, name)
+}
@@ -6,10 +6,15 @@ import (
 
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -23,14 +28,15 @@ var (
 
 // objectstorageResourceModel maps the resource schema data.
 type objectStorageResourceModel struct {
-	ID              types.String `tfsdk:"id"`
-	Cloudaccount    types.String `tfsdk:"cloudaccount"`
-	Name            types.String `tfsdk:"name"`
-	Versioned       types.Bool   `tfsdk:"versioned"`
-	Size            types.String `tfsdk:"size"`
-	Status          types.String `tfsdk:"status"`
-	PrivateEndpoint types.String `tfsdk:"private_endpoint"`
-	SecurityGroups  types.List   `tfsdk:"security_groups"`
+	ID              types.String   `tfsdk:"id"`
+	Cloudaccount    types.String   `tfsdk:"cloudaccount"`
+	Name            types.String   `tfsdk:"name"`
+	Versioned       types.Bool     `tfsdk:"versioned"`
+	Size            types.String   `tfsdk:"size"`
+	Status          types.String   `tfsdk:"status"`
+	PrivateEndpoint types.String   `tfsdk:"private_endpoint"`
+	SecurityGroups  types.List     `tfsdk:"security_groups"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
 }
 
 // NewObjectStorageResource is a helper function to simplify the provider implementation.
@@ -69,7 +75,7 @@ func (r *objectStorageResource) Metadata(_ context.Context, req resource.Metadat
 }
 
 // Schema defines the schema for the resource.
-func (r *objectStorageResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *objectStorageResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -77,12 +83,18 @@ func (r *objectStorageResource) Schema(_ context.Context, _ resource.SchemaReque
 			},
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"cloudaccount": schema.StringAttribute{
 				Computed: true,
 			},
 			"versioned": schema.BoolAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
 			},
 			"size": schema.StringAttribute{
 				Computed: true,
@@ -111,8 +123,13 @@ func (r *objectStorageResource) Schema(_ context.Context, _ resource.SchemaReque
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
 	}
-
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -126,6 +143,14 @@ func (r *objectStorageResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, itacservices.DefaultBucketTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	inArg := itacservices.ObjectBucketCreateRequest{
 		Metadata: struct {
 			Name string "json:\"name\""
@@ -192,6 +217,11 @@ func (r *objectStorageResource) Read(ctx context.Context, req resource.ReadReque
 	// Get refreshed order value from IDC Service
 	bucket, err := r.client.GetObjectBucketByResourceId(ctx, state.ID.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "object bucket not found, removing it from state", map[string]any{"id": state.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC Object Bucket resource",
 			"Could not read IDC Object Bucket resource ID "+state.ID.ValueString()+": "+err.Error(),
@@ -231,8 +261,10 @@ func (r *objectStorageResource) Read(ctx context.Context, req resource.ReadReque
 	}
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update only changes the timeouts, every other configurable attribute of a
+// bucket requires its replacement.
 func (r *objectStorageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
 }
 
 func (r *objectStorageResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -250,9 +282,17 @@ func (r *objectStorageResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, itacservices.DefaultBucketTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteBucketByResourceId(ctx, state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error Deleting IDC Object Storage Bucket resource",
 			"Could not delete IDC Object Storage Bucket resource ID "+state.ID.ValueString()+": "+err.Error(),
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccObjectStorageResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckObjectStorageDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccObjectStorageResourceConfig(name, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket.test", "versioned", "false"),
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket.test", "status", "ready"),
+					resource.TestCheckResourceAttrSet("intelcloud_object_storage_bucket.test", "id"),
+					resource.TestCheckResourceAttrSet("intelcloud_object_storage_bucket.test", "private_endpoint"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "intelcloud_object_storage_bucket.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update testing, versioning can only be set on a new bucket
+			{
+				Config: testAccObjectStorageResourceConfig(name, true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_object_storage_bucket.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_object_storage_bucket.test", "versioned", "true"),
+			},
+			// Drift testing, a bucket deleted outside of Terraform is recreated
+			{
+				Config:             testAccObjectStorageResourceConfig(name, true),
+				Check:              testAccDeleteOutOfBand("intelcloud_object_storage_bucket.test", deleteObjectStorageBucket),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccObjectStorageResourceConfig(name, true),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_object_storage_bucket.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccObjectStorageResourceConfig(name string, versioned bool) string {
+	return fmt.Sprintf(`
+resource "intelcloud_object_storage_bucket" "test" {
+  name      = %[1]q
+  versioned = %[2]t
+}
+`, name, versioned)
+}
+
+func deleteObjectStorageBucket(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteBucketByResourceId(ctx, attrs["id"])
+}
+
+var testAccCheckObjectStorageDestroy = testAccCheckDestroyed("intelcloud_object_storage_bucket", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetObjectBucketByResourceId(ctx, attrs["id"])
+	return err
+})
@@ -6,11 +6,17 @@ import (
 
 	"terraform-provider-intelcloud/internal/models"
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -32,6 +38,7 @@ type objectStorageUserResourceModel struct {
 	AllowActions  []types.String   `tfsdk:"allow_actions"`
 	AllowPolicies ObjectUserPolicy `tfsdk:"allow_policies"`
 	AccessInfo    types.Object     `tfsdk:"access_info"`
+	Timeouts      timeouts.Value   `tfsdk:"timeouts"`
 }
 
 type ObjectUserPolicy struct {
@@ -75,7 +82,7 @@ func (r *objectStorageUserResource) Metadata(_ context.Context, req resource.Met
 }
 
 // Schema defines the schema for the resource.
-func (r *objectStorageUserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *objectStorageUserResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -83,9 +90,15 @@ func (r *objectStorageUserResource) Schema(_ context.Context, _ resource.SchemaR
 			},
 			"name": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"bucket_id": schema.StringAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"cloudaccount": schema.StringAttribute{
 				Computed: true,
@@ -96,9 +109,15 @@ func (r *objectStorageUserResource) Schema(_ context.Context, _ resource.SchemaR
 			"allow_actions": schema.ListAttribute{
 				ElementType: types.StringType,
 				Required:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
 			},
 			"allow_policies": schema.SingleNestedAttribute{
 				Required: true,
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
 				Attributes: map[string]schema.Attribute{
 					"path_prefix": schema.StringAttribute{
 						Required: true,
@@ -117,6 +136,12 @@ func (r *objectStorageUserResource) Schema(_ context.Context, _ resource.SchemaR
 				Computed: true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
 	}
 
 }
@@ -132,6 +157,14 @@ func (r *objectStorageUserResource) Create(ctx context.Context, req resource.Cre
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	actions := []string{}
 	for _, a := range plan.AllowActions {
 		actions = append(actions, a.ValueString())
@@ -192,9 +225,14 @@ func (r *objectStorageUserResource) Create(ctx context.Context, req resource.Cre
 
 // Read refreshes the Terraform state with the latest data.
 func (r *objectStorageUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
+	// Get current state. Only the id is known after an import.
 	var state objectStorageUserResourceModel
-	diags := req.State.Get(ctx, &state)
+	diags := req.State.GetAttribute(ctx, path.Root("id"), &state.ID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.GetAttribute(ctx, path.Root("timeouts"), &state.Timeouts)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -203,6 +241,11 @@ func (r *objectStorageUserResource) Read(ctx context.Context, req resource.ReadR
 	// Get refreshed order value from IDC Service
 	user, err := r.client.GetObjectUserByUserId(ctx, state.ID.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "object bucket user not found, removing it from state", map[string]any{"id": state.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC Object Bucket user resource",
 			"Could not read IDC Object Bucket user ID "+state.ID.ValueString()+": "+err.Error(),
@@ -215,6 +258,21 @@ func (r *objectStorageUserResource) Read(ctx context.Context, req resource.ReadR
 	state.Name = types.StringValue(user.Metadata.Name)
 	state.Status = types.StringValue(mapObjectUserStatus(user.Status.Phase))
 
+	// The resource manages a single bucket policy.
+	if len(user.Spec) > 0 {
+		policy := user.Spec[0]
+		state.BucketId = types.StringValue(policy.BucketId)
+		state.AllowActions = []types.String{}
+		for _, a := range policy.Actions {
+			state.AllowActions = append(state.AllowActions, types.StringValue(a))
+		}
+		state.AllowPolicies.PathPrefix = types.StringValue(policy.Prefix)
+		state.AllowPolicies.Policies = []types.String{}
+		for _, p := range policy.Permissions {
+			state.AllowPolicies.Policies = append(state.AllowPolicies.Policies, types.StringValue(p))
+		}
+	}
+
 	creds := models.ObjectUserAccessModel{
 		AccessKey: types.StringValue(user.Status.Principal.Credentials.AccessKey),
 		SecretKey: types.StringValue(user.Status.Principal.Credentials.SecretKey),
@@ -234,8 +292,10 @@ func (r *objectStorageUserResource) Read(ctx context.Context, req resource.ReadR
 	}
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update only changes the timeouts, every other configurable attribute of a
+// bucket user requires its replacement.
 func (r *objectStorageUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
 }
 
 func (r *objectStorageUserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -253,9 +313,17 @@ func (r *objectStorageUserResource) Delete(ctx context.Context, req resource.Del
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteObjectUserByResourceId(ctx, state.ID.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error Deleting IDC Object Storage Bucket user resource",
 			"Could not delete IDC Object Storage Bucket user resource ID "+state.ID.ValueString()+": "+err.Error(),
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccObjectStorageUserResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckObjectStorageUserDestroy,
+			testAccCheckObjectStorageDestroy,
+		),
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccObjectStorageUserResourceConfig(name, "GetBucketLocation"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket_user.test", "name", name),
+					resource.TestCheckResourceAttrPair("intelcloud_object_storage_bucket_user.test", "bucket_id", "intelcloud_object_storage_bucket.test", "id"),
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket_user.test", "allow_actions.#", "1"),
+					resource.TestCheckResourceAttr("intelcloud_object_storage_bucket_user.test", "status", "ready"),
+					resource.TestCheckResourceAttrSet("intelcloud_object_storage_bucket_user.test", "access_info.access_key"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:      "intelcloud_object_storage_bucket_user.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			// Update testing, a user with other policies replaces the old one
+			{
+				Config: testAccObjectStorageUserResourceConfig(name, "GetBucketPolicy"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_object_storage_bucket_user.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_object_storage_bucket_user.test", "allow_actions.0", "GetBucketPolicy"),
+			},
+			// Drift testing, a user deleted outside of Terraform is recreated
+			{
+				Config:             testAccObjectStorageUserResourceConfig(name, "GetBucketPolicy"),
+				Check:              testAccDeleteOutOfBand("intelcloud_object_storage_bucket_user.test", deleteObjectStorageUser),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccObjectStorageUserResourceConfig(name, "GetBucketPolicy"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_object_storage_bucket_user.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func testAccObjectStorageUserResourceConfig(name, action string) string {
+	return testAccObjectStorageResourceConfig(name, false) + fmt.Sprintf(`
+resource "intelcloud_object_storage_bucket_user" "test" {
+  name          = %[1]q
+  bucket_id     = intelcloud_object_storage_bucket.test.id
+  allow_actions = [%[2]q]
+  allow_policies = {
+    path_prefix = "/"
+    policies    = ["ReadBucket"]
+  }
+}
+`, name, action)
+}
+
+func deleteObjectStorageUser(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteObjectUserByResourceId(ctx, attrs["id"])
+}
+
+var testAccCheckObjectStorageUserDestroy = testAccCheckDestroyed("intelcloud_object_storage_bucket_user", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetObjectUserByUserId(ctx, attrs["id"])
+	return err
+})
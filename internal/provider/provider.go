@@ -5,9 +5,15 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -24,11 +30,30 @@ var (
 
 // idcProviderModel maps provider schema data to a Go type.
 type idcProviderModel struct {
-	Region       types.String `tfsdk:"region"`
-	Cloudaccount types.String `tfsdk:"cloudaccount"`
-	APIToken     types.String `tfsdk:"apitoken"`
-	ClientId     types.String `tfsdk:"clientid"`
-	ClientSecret types.String `tfsdk:"clientsecret"`
+	Region          types.String `tfsdk:"region"`
+	Cloudaccount    types.String `tfsdk:"cloudaccount"`
+	APIToken        types.String `tfsdk:"apitoken"`
+	ClientId        types.String `tfsdk:"clientid"`
+	ClientSecret    types.String `tfsdk:"clientsecret"`
+	Endpoint        types.String `tfsdk:"endpoint"`
+	TokenEndpoint   types.String `tfsdk:"token_endpoint"`
+	Profile         types.String `tfsdk:"profile"`
+	CredentialsFile types.String `tfsdk:"credentials_file"`
+	Exec            *execModel   `tfsdk:"exec"`
+	MaxRetries      types.Int64  `tfsdk:"max_retries"`
+	RetryMaxWait    types.Int64  `tfsdk:"retry_max_wait"`
+	ProxyURL        types.String `tfsdk:"proxy_url"`
+	CABundle        types.String `tfsdk:"ca_bundle"`
+	ClientCert      types.String `tfsdk:"client_certificate"`
+	ClientKey       types.String `tfsdk:"client_key"`
+	RequestTimeout  types.Int64  `tfsdk:"request_timeout"`
+}
+
+// execModel configures an external command that supplies credentials.
+type execModel struct {
+	Command types.String            `tfsdk:"command"`
+	Args    []types.String          `tfsdk:"args"`
+	Env     map[string]types.String `tfsdk:"env"`
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -65,13 +90,72 @@ func (p *idcProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *
 				Optional: true,
 			},
 			"apitoken": schema.StringAttribute{
-				Optional: true,
+				Optional:  true,
+				Sensitive: true,
 			},
 			"clientid": schema.StringAttribute{
 				Optional: true,
 			},
 			"clientsecret": schema.StringAttribute{
+				Optional:  true,
+				Sensitive: true,
+			},
+			"endpoint": schema.StringAttribute{
+				Optional: true,
+			},
+			"token_endpoint": schema.StringAttribute{
+				Optional: true,
+			},
+			"profile": schema.StringAttribute{
+				Optional: true,
+			},
+			"credentials_file": schema.StringAttribute{
+				Optional: true,
+			},
+			"exec": schema.SingleNestedAttribute{
 				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"command": schema.StringAttribute{
+						Required: true,
+					},
+					"args": schema.ListAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"env": schema.MapAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of times a failed API call is retried. Defaults to 3.",
+			},
+			"retry_max_wait": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of seconds to wait between two attempts of an API call. Defaults to 30.",
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the proxy used for all API calls. Defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+			},
+			"ca_bundle": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded CA certificates, or the path of a file holding them, trusted in addition to the system roots.",
+			},
+			"client_certificate": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded client certificate, or the path of a file holding it, presented for mutual TLS.",
+			},
+			"client_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "PEM encoded private key of the client certificate, or the path of a file holding it.",
+			},
+			"request_timeout": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of seconds a single API request may take. Defaults to 60.",
 			},
 		},
 	}
@@ -84,8 +168,10 @@ func (p *idcProvider) Configure(ctx context.Context, req provider.ConfigureReque
 
 	region := os.Getenv("ITAC_REGION")
 	cloudaccount := os.Getenv("ITAC_CLOUDACCOUNT")
-	clientid := os.Getenv("ITAC_CLIENT_ID")
-	clientsecret := os.Getenv("ITAC_CLIENT_SECRET")
+	serviceEndpoint := os.Getenv("ITAC_ENDPOINT")
+	clientTokenEndpoint := os.Getenv("ITAC_TOKEN_ENDPOINT")
+	profileName := os.Getenv("ITAC_PROFILE")
+	credentialsFile := os.Getenv("ITAC_CREDENTIALS_FILE")
 
 	// Retrieve provider data from configuration
 	var config idcProviderModel
@@ -103,13 +189,137 @@ func (p *idcProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		cloudaccount = config.Cloudaccount.ValueString()
 	}
 
-	if !config.ClientId.IsNull() {
-		clientid = config.ClientId.ValueString()
+	if !config.Endpoint.IsNull() {
+		serviceEndpoint = config.Endpoint.ValueString()
+	}
+
+	if !config.TokenEndpoint.IsNull() {
+		clientTokenEndpoint = config.TokenEndpoint.ValueString()
+	}
+
+	if !config.Profile.IsNull() {
+		profileName = config.Profile.ValueString()
+	}
+
+	if !config.CredentialsFile.IsNull() {
+		credentialsFile = config.CredentialsFile.ValueString()
+	}
+
+	// Values missing from the configuration and environment fall back to the
+	// selected profile of the shared credentials file. The file and profile
+	// are only required to exist when they were chosen explicitly.
+	profileRequired := profileName != "" || credentialsFile != ""
+	if profileName == "" {
+		profileName = defaultProfileName
+	}
+	if credentialsFile == "" {
+		credentialsFile = defaultCredentialsFile()
+	}
+
+	profile, err := loadCredentialsProfile(ctx, credentialsFile, profileName, profileRequired)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Unable to Load ITAC Credentials Profile",
+			"The provider cannot read the ITAC credentials profile "+profileName+". "+
+				"Set the profile and credentials_file values in the configuration or use the ITAC_PROFILE and "+
+				"ITAC_CREDENTIALS_FILE environment variables to select an existing profile.\n\n"+
+				"Error: "+err.Error(),
+		)
+		return
+	}
+
+	if region == "" {
+		region = profile.Region
+	}
+
+	if cloudaccount == "" {
+		cloudaccount = profile.Cloudaccount
+	}
+
+	// Credentials are taken from the first source offering them: the
+	// provider configuration, the environment, the credentials profile and
+	// finally the exec command.
+	chain := []credentialProvider{
+		&staticCredentialProvider{
+			name: "provider configuration",
+			creds: clientCredentials{
+				APIToken:     config.APIToken.ValueString(),
+				ClientId:     config.ClientId.ValueString(),
+				ClientSecret: config.ClientSecret.ValueString(),
+			},
+		},
+		&staticCredentialProvider{
+			name: "environment",
+			creds: clientCredentials{
+				APIToken:     os.Getenv("ITAC_API_TOKEN"),
+				ClientId:     os.Getenv("ITAC_CLIENT_ID"),
+				ClientSecret: os.Getenv("ITAC_CLIENT_SECRET"),
+			},
+		},
+		&staticCredentialProvider{
+			name: "credentials profile " + profileName,
+			creds: clientCredentials{
+				APIToken:     profile.APIToken,
+				ClientId:     profile.ClientId,
+				ClientSecret: profile.ClientSecret,
+			},
+		},
+	}
+
+	if config.Exec != nil {
+		execProvider := &execCredentialProvider{
+			command: config.Exec.Command.ValueString(),
+			env:     map[string]string{},
+		}
+		for _, a := range config.Exec.Args {
+			execProvider.args = append(execProvider.args, a.ValueString())
+		}
+		for k, v := range config.Exec.Env {
+			execProvider.env[k] = v.ValueString()
+		}
+		chain = append(chain, execProvider)
 	}
 
-	if !config.ClientSecret.IsNull() {
-		clientsecret = config.ClientSecret.ValueString()
+	creds, err := resolveCredentials(ctx, chain)
+	if err != nil {
+		var provErr *credentialProviderError
+		switch {
+		case errors.Is(err, errConflictingCredentials):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("apitoken"),
+				"Conflicting ITAC Credentials",
+				"The provider cannot decide how to authenticate as both an ITAC API token and ITAC client credentials are set. "+
+					"Set either the apitoken value (or the ITAC_API_TOKEN environment variable), or the clientid and clientsecret values "+
+					"(or the ITAC_CLIENT_ID and ITAC_CLIENT_SECRET environment variables), but not both.\n\n"+
+					"Error: "+err.Error(),
+			)
+		case errors.As(err, &provErr) && isExecProvider(provErr.provider):
+			resp.Diagnostics.AddAttributeError(
+				path.Root("exec"),
+				"Unable to Obtain ITAC Credentials",
+				"The provider cannot obtain ITAC credentials from the "+provErr.provider.Name()+". "+
+					"Ensure the command prints a JSON document with either a token or a client_id and client_secret.\n\n"+
+					"Error: "+provErr.err.Error(),
+			)
+		case provErr != nil:
+			resp.Diagnostics.AddError(
+				"Unable to Obtain ITAC Credentials",
+				"The provider cannot obtain ITAC credentials from the "+provErr.provider.Name()+".\n\n"+
+					"Error: "+provErr.err.Error(),
+			)
+		default:
+			resp.Diagnostics.AddError(
+				"Unable to Obtain ITAC Credentials",
+				"The provider cannot obtain ITAC credentials.\n\n"+
+					"Error: "+err.Error(),
+			)
+		}
+		return
 	}
+	apitoken := creds.APIToken
+	clientid := creds.ClientId
+	clientsecret := creds.ClientSecret
 
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
@@ -134,22 +344,24 @@ func (p *idcProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		)
 	}
 
-	if clientid == "" {
+	if apitoken == "" && clientid == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("clientid"),
 			"Missing ITAC Client Id",
 			"The provider cannot create the ITAC Client Id as there is a missing or empty value for the ITAC client id. "+
 				"Set the clientid value in the configuration or use the ITAC_CLIENT_ID environment variable. "+
+				"Alternatively, set the apitoken value or the ITAC_API_TOKEN environment variable. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
-	if clientsecret == "" {
+	if apitoken == "" && clientsecret == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("clientsecret"),
 			"Missing ITAC Client secret",
 			"The provider cannot create the ITAC client secret as there is a missing or empty value for the ITAC client secret "+
 				"Set the clientsecret value in the configuration or use the ITAC_CLIENT_SECRET environment variable. "+
+				"Alternatively, set the apitoken value or the ITAC_API_TOKEN environment variable. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
@@ -158,10 +370,111 @@ func (p *idcProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		return
 	}
 
-	clientTokenEndpoint, serviceEndpoint := discoverITACServiceEndpoint(region)
+	// Endpoints set explicitly take precedence over the ones known for the region.
+	if serviceEndpoint == "" || (apitoken == "" && clientTokenEndpoint == "") {
+		discoveredTokenEndpoint, discoveredServiceEndpoint, err := discoverITACServiceEndpoint(region)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("region"),
+				"Unknown ITAC API Region",
+				"The provider cannot determine the ITAC API endpoints for region "+region+". "+
+					"Use one of the known regions, or set the endpoint and token_endpoint values in the configuration "+
+					"or use the ITAC_ENDPOINT and ITAC_TOKEN_ENDPOINT environment variables.",
+			)
+			return
+		}
+		if serviceEndpoint == "" {
+			serviceEndpoint = discoveredServiceEndpoint
+		}
+		if clientTokenEndpoint == "" {
+			clientTokenEndpoint = discoveredTokenEndpoint
+		}
+	}
+
+	if err := validateEndpoint(serviceEndpoint); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("endpoint"),
+			"Invalid ITAC API Endpoint",
+			"The provider cannot use the ITAC API endpoint "+serviceEndpoint+": "+err.Error(),
+		)
+	}
+
+	if apitoken == "" {
+		if err := validateEndpoint(clientTokenEndpoint); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("token_endpoint"),
+				"Invalid ITAC Token Endpoint",
+				"The provider cannot use the ITAC token endpoint "+clientTokenEndpoint+": "+err.Error(),
+			)
+		}
+	}
+
+	retryPolicy := common.DefaultRetryPolicy
+	if !config.MaxRetries.IsNull() {
+		if config.MaxRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_retries"),
+				"Invalid Retry Configuration",
+				"The max_retries value must not be negative.",
+			)
+		}
+		retryPolicy.MaxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.RetryMaxWait.IsNull() {
+		if config.RetryMaxWait.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_max_wait"),
+				"Invalid Retry Configuration",
+				"The retry_max_wait value must be at least 1 second.",
+			)
+		}
+		retryPolicy.MaxWait = time.Duration(config.RetryMaxWait.ValueInt64()) * time.Second
+		if retryPolicy.MinWait > retryPolicy.MaxWait {
+			retryPolicy.MinWait = retryPolicy.MaxWait
+		}
+	}
+
+	if !config.RequestTimeout.IsNull() && config.RequestTimeout.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("request_timeout"),
+			"Invalid Request Timeout",
+			"The request_timeout value must be at least 1 second.",
+		)
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	httpClient, err := common.NewHTTPClient(common.TransportConfig{
+		ProxyURL:          config.ProxyURL.ValueString(),
+		CABundle:          config.CABundle.ValueString(),
+		ClientCertificate: config.ClientCert.ValueString(),
+		ClientKey:         config.ClientKey.ValueString(),
+		RequestTimeout:    time.Duration(config.RequestTimeout.ValueInt64()) * time.Second,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid ITAC HTTP Transport Configuration",
+			"The provider cannot set up its HTTP transport from the proxy_url, ca_bundle, client_certificate and client_key values.\n\n"+
+				"Error: "+err.Error(),
+		)
+		return
+	}
+	ctx = common.WithHTTPClient(ctx, httpClient)
+
+	serviceEndpoint = strings.TrimSuffix(serviceEndpoint, "/")
+	clientTokenEndpoint = strings.TrimSuffix(clientTokenEndpoint, "/")
 
 	// Create a new HashiCups client using the configuration values
-	client, err := itacservices.NewClient(ctx, &serviceEndpoint, &clientTokenEndpoint, &cloudaccount, &clientid, &clientsecret, &region)
+	var client *itacservices.IDCServicesClient
+	if execProvider, ok := creds.Source.(*execCredentialProvider); ok && apitoken != "" {
+		client, err = itacservices.NewClientWithTokenSource(ctx, &serviceEndpoint, &cloudaccount, &region, apitoken, creds.ExpiresAt, execProvider.tokenSource())
+	} else if apitoken != "" {
+		client, err = itacservices.NewClientWithToken(ctx, &serviceEndpoint, &cloudaccount, &apitoken, &region)
+	} else {
+		client, err = itacservices.NewClient(ctx, &serviceEndpoint, &clientTokenEndpoint, &cloudaccount, &clientid, &clientsecret, &region)
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Create ITAC API Client",
@@ -171,6 +484,7 @@ func (p *idcProvider) Configure(ctx context.Context, req provider.ConfigureReque
 		)
 		return
 	}
+	client.RetryPolicy = &retryPolicy
 
 	// Make the HashiCups client available during DataSource and Resource
 	// type Configure methods.
@@ -188,6 +502,7 @@ func (p *idcProvider) DataSources(_ context.Context) []func() datasource.DataSou
 		NewMachineImagesDataSource,
 		// NewKubernetesDataSource,
 		NewKubeconfigDataSource,
+		NewVNetsDataSource,
 	}
 }
 
@@ -202,22 +517,37 @@ func (p *idcProvider) Resources(_ context.Context) []func() resource.Resource {
 		NewIKSLBResource,
 		NewObjectStorageResource,
 		NewObjectStorageUserResource,
+		NewVNetResource,
 	}
 }
 
-func discoverITACServiceEndpoint(region string) (string, string) {
+// discoverITACServiceEndpoint returns the token and service endpoints of a
+// known region.
+func discoverITACServiceEndpoint(region string) (string, string, error) {
 	switch region {
 	case "us-staging-1":
-		return "https://client-token.staging.api.idcservice.net", "https://us-staging-1-sdk-api.eglb.intel.com"
+		return "https://client-token.staging.api.idcservice.net", "https://us-staging-1-sdk-api.eglb.intel.com", nil
 	case "us-region-1":
-		return "https://client-token.api.idcservice.net", "https://us-region-1-sdk-api.cloud.intel.com"
+		return "https://client-token.api.idcservice.net", "https://us-region-1-sdk-api.cloud.intel.com", nil
 	case "us-region-2":
-		return "https://client-token.api.idcservice.net", "https://us-region-2-sdk-api.cloud.intel.com"
+		return "https://client-token.api.idcservice.net", "https://us-region-2-sdk-api.cloud.intel.com", nil
 	case "us-region-3":
-		return "https://client-token.api.idcservice.net", "https://us-region-3-sdk-api.cloud.intel.com"
+		return "https://client-token.api.idcservice.net", "https://us-region-3-sdk-api.cloud.intel.com", nil
 	case "us-region-4":
-		return "https://client-token.api.idcservice.net", "https://us-region-4-sdk-api.cloud.intel.com"
+		return "https://client-token.api.idcservice.net", "https://us-region-4-sdk-api.cloud.intel.com", nil
 	default:
-		return "", ""
+		return "", "", fmt.Errorf("unknown region %q", region)
+	}
+}
+
+// validateEndpoint checks that an endpoint is an absolute http(s) URL.
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected an absolute http or https URL")
 	}
+	return nil
 }
@@ -4,10 +4,22 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+	"terraform-provider-intelcloud/pkg/itacservices/itacfake"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -15,11 +27,246 @@ import (
 // CLI command executed to create a provider server to which the CLI can
 // reattach.
 var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-	"scaffolding": providerserver.NewProtocol6WithError(New("test")()),
+	"intelcloud": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccInterruptingProviderFactories returns provider factories that
+// cancel the first apply of a resource of type typeName once the fake API
+// got a request matching method and pathPattern during that apply, as
+// Terraform cancels the requests in flight when it is interrupted.
+func testAccInterruptingProviderFactories(typeName, method, pathPattern string) map[string]func() (tfprotov6.ProviderServer, error) {
+	var interrupted atomic.Bool
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"intelcloud": func() (tfprotov6.ProviderServer, error) {
+			server, err := providerserver.NewProtocol6WithError(New("test")())()
+			if err != nil {
+				return nil, err
+			}
+			return &interruptingProviderServer{
+				ProviderServer: server,
+				interrupt: func(req *tfprotov6.ApplyResourceChangeRequest) bool {
+					return req.TypeName == typeName && interrupted.CompareAndSwap(false, true)
+				},
+				method:      method,
+				pathPattern: pathPattern,
+			}, nil
+		},
+	}
+}
+
+// interruptingProviderServer cancels the applies selected by interrupt once
+// the fake API got a request matching method and pathPattern.
+type interruptingProviderServer struct {
+	tfprotov6.ProviderServer
+	interrupt   func(req *tfprotov6.ApplyResourceChangeRequest) bool
+	method      string
+	pathPattern string
+}
+
+func (s *interruptingProviderServer) ApplyResourceChange(ctx context.Context, req *tfprotov6.ApplyResourceChangeRequest) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	if !s.interrupt(req) {
+		return s.ProviderServer.ApplyResourceChange(ctx, req)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	seen := testAccFakeAPI.RequestCount(s.method, s.pathPattern)
+	go func() {
+		for testAccFakeAPI.RequestCount(s.method, s.pathPattern) == seen {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		cancel()
+	}()
+	return s.ProviderServer.ApplyResourceChange(ctx, req)
+}
+
+// Acceptance tests run against the API configured with the usual ITAC_*
+// environment variables. Setting ITAC_ACC_FAKE runs them against an
+// in-process fake of the API instead, so they need no cloud account.
+const (
+	fakeCloudaccount = "123456789012"
+	fakeRegion       = "us-fake-1"
+	fakeClientID     = "acctest-client"
+	fakeClientSecret = "acctest-secret"
+)
+
+// Names of the catalog entries used by the instance and node group tests.
+// All are served by the fake API.
+const (
+	testAccInstanceType      = "vm-spr-sml"
+	testAccOtherInstanceType = "vm-spr-med"
+	testAccMachineImage      = "ubuntu-2204-jammy-v20240308"
+)
+
+func TestMain(m *testing.M) {
+	cleanup := startFakeAPI()
+	// resource.TestMain runs the sweepers instead of the tests when -sweep
+	// is set.
+	resource.TestMain(testRunner{m: m, cleanup: cleanup})
+	cleanup()
+}
+
+// testRunner tears down the fake API before resource.TestMain exits.
+type testRunner struct {
+	m       *testing.M
+	cleanup func()
+}
+
+func (r testRunner) Run() int {
+	code := r.m.Run()
+	r.cleanup()
+	return code
+}
+
+// testAccFakeAPI is the fake API the acceptance tests run against, nil when
+// they run against the real API.
+var testAccFakeAPI *itacfake.Server
+
+// startFakeAPI points the provider at an in-process fake API when
+// ITAC_ACC_FAKE is set and returns a function that stops it.
+func startFakeAPI() func() {
+	if os.Getenv("ITAC_ACC_FAKE") == "" {
+		return func() {}
+	}
+	srv := itacfake.New(itacfake.Config{ClientID: fakeClientID, ClientSecret: fakeClientSecret})
+	testAccFakeAPI = srv
+
+	for k, v := range map[string]string{
+		"ITAC_ENDPOINT":       srv.URL,
+		"ITAC_TOKEN_ENDPOINT": srv.URL,
+		"ITAC_REGION":         fakeRegion,
+		"ITAC_CLOUDACCOUNT":   fakeCloudaccount,
+		"ITAC_CLIENT_ID":      fakeClientID,
+		"ITAC_CLIENT_SECRET":  fakeClientSecret,
+	} {
+		os.Setenv(k, v)
+	}
+	os.Unsetenv("ITAC_API_TOKEN")
+
+	var once sync.Once
+	return func() { once.Do(srv.Close) }
 }
 
 func testAccPreCheck(t *testing.T) {
-	// You can add code here to run prior to any test case execution, for example assertions
-	// about the appropriate environment variables being set are common to see in a pre-check
-	// function.
+	for _, k := range []string{"ITAC_REGION", "ITAC_CLOUDACCOUNT"} {
+		if os.Getenv(k) == "" {
+			t.Fatalf("%s must be set for acceptance tests", k)
+		}
+	}
+	if os.Getenv("ITAC_API_TOKEN") == "" && (os.Getenv("ITAC_CLIENT_ID") == "" || os.Getenv("ITAC_CLIENT_SECRET") == "") {
+		t.Fatal("ITAC_API_TOKEN or ITAC_CLIENT_ID and ITAC_CLIENT_SECRET must be set for acceptance tests")
+	}
+}
+
+// testAccPreCheckFake skips tests of scenarios that only the fake API can
+// set up.
+func testAccPreCheckFake(t *testing.T) {
+	if testAccFakeAPI == nil {
+		t.Skip("ITAC_ACC_FAKE must be set for tests of failure scenarios")
+	}
+	testAccPreCheck(t)
+}
+
+var (
+	testAccClientOnce sync.Once
+	testAccClient     *itacservices.IDCServicesClient
+	testAccClientErr  error
+)
+
+// testAccAPIClient returns a client configured like the provider under test,
+// for checks that look at the API directly.
+func testAccAPIClient() (*itacservices.IDCServicesClient, error) {
+	testAccClientOnce.Do(func() {
+		testAccClient, testAccClientErr = newTestAPIClient(os.Getenv("ITAC_REGION"))
+	})
+	return testAccClient, testAccClientErr
+}
+
+// newTestAPIClient returns a client for region configured from the ITAC_*
+// environment variables.
+func newTestAPIClient(region string) (*itacservices.IDCServicesClient, error) {
+	ctx := context.Background()
+	cloudaccount := os.Getenv("ITAC_CLOUDACCOUNT")
+	endpoint := os.Getenv("ITAC_ENDPOINT")
+	tokenEndpoint := os.Getenv("ITAC_TOKEN_ENDPOINT")
+	if endpoint == "" || tokenEndpoint == "" {
+		discoveredToken, discoveredEndpoint, err := discoverITACServiceEndpoint(region)
+		if err != nil {
+			return nil, err
+		}
+		if endpoint == "" {
+			endpoint = discoveredEndpoint
+		}
+		if tokenEndpoint == "" {
+			tokenEndpoint = discoveredToken
+		}
+	}
+
+	if token := os.Getenv("ITAC_API_TOKEN"); token != "" {
+		return itacservices.NewClientWithToken(ctx, &endpoint, &cloudaccount, &token, &region)
+	}
+	clientID, clientSecret := os.Getenv("ITAC_CLIENT_ID"), os.Getenv("ITAC_CLIENT_SECRET")
+	return itacservices.NewClient(ctx, &endpoint, &tokenEndpoint, &cloudaccount, &clientID, &clientSecret, &region)
+}
+
+// testAccCheckDestroyed returns a CheckDestroy function that fails if any
+// resource of type resourceType in the state can still be read with get.
+func testAccCheckDestroyed(resourceType string, get func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client, err := testAccAPIClient()
+		if err != nil {
+			return err
+		}
+		for name, rs := range s.RootModule().Resources {
+			if rs.Type != resourceType {
+				continue
+			}
+			err := get(context.Background(), client, rs.Primary.Attributes)
+			if err == nil {
+				return fmt.Errorf("%s still exists", name)
+			}
+			if !common.IsNotFound(err) {
+				return fmt.Errorf("error checking that %s is destroyed: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// testAccDeleteOutOfBand returns a check that deletes the resource at
+// address through the API, behind Terraform's back, to test drift handling.
+func testAccDeleteOutOfBand(address string, del func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error) func(*terraform.State) error {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return fmt.Errorf("%s not found in state", address)
+		}
+		client, err := testAccAPIClient()
+		if err != nil {
+			return err
+		}
+		return del(context.Background(), client, rs.Primary.Attributes)
+	}
+}
+
+// testAccImportStateIDFunc returns an ImportStateIdFunc that joins the given
+// attributes of the resource at address with a slash.
+func testAccImportStateIDFunc(address string, attrs ...string) func(*terraform.State) (string, error) {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[address]
+		if !ok {
+			return "", fmt.Errorf("%s not found in state", address)
+		}
+		id := ""
+		for i, attr := range attrs {
+			if i > 0 {
+				id += "/"
+			}
+			id += rs.Primary.Attributes[attr]
+		}
+		return id, nil
+	}
 }
@@ -128,6 +128,7 @@ func (d *sshkeysDataSource) Read(ctx context.Context, req datasource.ReadRequest
 	for _, key := range sshkeyList.SSHKey {
 		sshkeyModel := sshkeyModel{
 			Metadata: resourceMetadata{
+				ResourceId:   types.StringValue(key.Metadata.ResourceId),
 				Cloudaccount: types.StringValue(key.Metadata.Cloudaccount),
 				Name:         types.StringValue(key.Metadata.Name),
 			},
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccSSHKeyDataSource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSSHKeyResourceConfig(name, testAccSSHPublicKey) + `
+data "intelcloud_sshkey" "test" {
+  depends_on = [intelcloud_sshkey.test]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.intelcloud_sshkey.test", "sshkeys.*", map[string]string{
+						"metadata.name":       name,
+						"spec.ssh_public_key": testAccSSHPublicKey,
+					}),
+				),
+			},
+		},
+	})
+}
@@ -6,23 +6,31 @@ import (
 	"time"
 
 	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &sshKeyResource{}
-	_ resource.ResourceWithConfigure = &sshKeyResource{}
+	_ resource.Resource                = &sshKeyResource{}
+	_ resource.ResourceWithConfigure   = &sshKeyResource{}
+	_ resource.ResourceWithImportState = &sshKeyResource{}
 )
 
 // orderSSHKeyModel maps the resource schema data.
 type sshKeyResourceModel struct {
-	Metadata resourceMetadata `tfsdk:"metadata"`
-	Spec     sshkeySpec       `tfsdk:"spec"`
+	Metadata      resourceMetadata `tfsdk:"metadata"`
+	Spec          sshkeySpec       `tfsdk:"spec"`
+	AdoptExisting types.Bool       `tfsdk:"adopt_existing"`
+	Timeouts      timeouts.Value   `tfsdk:"timeouts"`
 }
 
 // NewOrderFilesystem is a helper function to simplify the provider implementation.
@@ -61,7 +69,7 @@ func (r *sshKeyResource) Metadata(_ context.Context, req resource.MetadataReques
 }
 
 // Schema defines the schema for the resource.
-func (r *sshKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *sshKeyResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"metadata": schema.SingleNestedAttribute{
@@ -75,6 +83,9 @@ func (r *sshKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 					},
 					"name": schema.StringAttribute{
 						Required: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 					"createdat": schema.StringAttribute{
 						Computed: true,
@@ -86,12 +97,29 @@ func (r *sshKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Attributes: map[string]schema.Attribute{
 					"ssh_public_key": schema.StringAttribute{
 						Required: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 					"owner_email": schema.StringAttribute{
 						Required: true,
+						PlanModifiers: []planmodifier.String{
+							stringplanmodifier.RequiresReplace(),
+						},
 					},
 				},
 			},
+			"adopt_existing": schema.BoolAttribute{
+				Optional: true,
+				Description: "Adopt an existing key with the same name, public key and owner email " +
+					"instead of creating one. The adopted key is deleted when the resource is destroyed.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -107,6 +135,14 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	inArg := itacservices.SSHKeyCreateRequest{
 		Metadata: struct {
 			Name string "json:\"name\""
@@ -121,14 +157,48 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 			OwnerEmail:   plan.Spec.OwnerEmail.ValueString(),
 		},
 	}
-	tflog.Info(ctx, "making a call to IDC Service for create sshkey")
-	sshkeyCreateResp, err := r.client.CreateSSHkey(ctx, &inArg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating order",
-			"Could not create order, unexpected error: "+err.Error(),
-		)
-		return
+	var sshkeyCreateResp *itacservices.SSHKey
+	if plan.AdoptExisting.ValueBool() {
+		existing, err := r.client.GetSSHKeyByName(ctx, inArg.Metadata.Name)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating order",
+				"Could not look up an existing sshkey named "+inArg.Metadata.Name+": "+err.Error(),
+			)
+			return
+		}
+		if existing != nil && !existing.Matches(&inArg) {
+			resp.Diagnostics.AddAttributeError(path.Root("metadata").AtName("name"),
+				"Error adopting existing sshkey",
+				fmt.Sprintf("The sshkey %s named %q has a public key or owner email that does not match the configuration.",
+					existing.Metadata.ResourceId, existing.Metadata.Name),
+			)
+			return
+		}
+		if existing != nil {
+			tflog.Info(ctx, "adopting existing sshkey", map[string]any{"id": existing.Metadata.ResourceId})
+			sshkeyCreateResp = existing
+		}
+	}
+
+	if sshkeyCreateResp == nil {
+		tflog.Info(ctx, "making a call to IDC Service for create sshkey")
+		var err error
+		sshkeyCreateResp, err = r.client.CreateSSHkey(ctx, &inArg)
+		if err != nil {
+			if nameTaken(ctx, err, func(ctx context.Context) (bool, error) {
+				existing, err := r.client.GetSSHKeyByName(ctx, inArg.Metadata.Name)
+				return existing != nil, err
+			}) {
+				addNameTakenError(&resp.Diagnostics, path.Root("metadata").AtName("name"), "sshkey", inArg.Metadata.Name, err)
+				return
+			}
+			resp.Diagnostics.AddError(
+				"Error creating order",
+				"Could not create order, unexpected error: "+err.Error(),
+			)
+			return
+		}
 	}
 
 	// Map response body to schema and populate Computed attribute values
@@ -147,9 +217,19 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 
 // Read refreshes the Terraform state with the latest data.
 func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
+	// Get current state. Only the metadata is known after an import.
 	var state sshKeyResourceModel
-	diags := req.State.Get(ctx, &state)
+	diags := req.State.GetAttribute(ctx, path.Root("metadata"), &state.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.GetAttribute(ctx, path.Root("timeouts"), &state.Timeouts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = req.State.GetAttribute(ctx, path.Root("adopt_existing"), &state.AdoptExisting)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -158,6 +238,11 @@ func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, res
 	// Get refreshed order value from IDC Service
 	sshkey, err := r.client.GetSSHKeyByResourceId(ctx, state.Metadata.ResourceId.ValueString())
 	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "sshkey not found, removing it from state", map[string]any{"id": state.Metadata.ResourceId.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error Reading IDC SSHKey resource",
 			"Could not read IDC SSHKey resource ID "+state.Metadata.ResourceId.ValueString()+": "+err.Error(),
@@ -169,9 +254,21 @@ func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, res
 		ResourceId:   types.StringValue(sshkey.Metadata.ResourceId),
 		Cloudaccount: types.StringValue(sshkey.Metadata.Cloudaccount),
 		Name:         types.StringValue(sshkey.Metadata.Name),
+		CreatedAt:    state.Metadata.CreatedAt,
+	}
+	// An adopted key may differ from the configured one in its comment,
+	// keep the configured form of the same key.
+	var publicKey types.String
+	diags = req.State.GetAttribute(ctx, path.Root("spec").AtName("ssh_public_key"), &publicKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if publicKey.IsNull() || !itacservices.SameSSHPublicKey(publicKey.ValueString(), sshkey.Spec.SSHPublicKey) {
+		publicKey = types.StringValue(sshkey.Spec.SSHPublicKey)
 	}
 	state.Spec = sshkeySpec{
-		SSHPublicKey: types.StringValue(sshkey.Spec.SSHPublicKey),
+		SSHPublicKey: publicKey,
 		OwnerEmail:   types.StringValue(sshkey.Spec.OwnerEmail),
 	}
 
@@ -184,8 +281,11 @@ func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, res
 
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update only changes the timeouts and adopt_existing, every other attribute
+// of an sshkey requires its replacement.
 func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
+	updateAdoptExisting(ctx, req, resp)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -198,9 +298,17 @@ func (r *sshKeyResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// Delete the order from IDC Services
 	err := r.client.DeleteSSHKeyByResourceId(ctx, state.Metadata.ResourceId.ValueString())
-	if err != nil {
+	if err != nil && !common.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Error Deleting IDC SSHKey resource",
 			"Could not delete IDC SSHKey resource ID "+state.Metadata.ResourceId.ValueString()+": "+err.Error(),
@@ -208,3 +316,8 @@ func (r *sshKeyResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 }
+
+func (r *sshKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to metadata.resourceid attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("metadata").AtName("resourceid"), req, resp)
+}
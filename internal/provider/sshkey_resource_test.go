@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+const (
+	testAccSSHPublicKey      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDqc7zJkGbI5H1bTXTmDqfpYtYaw5zTzzNQnOy3EhB1E acctest@example.com"
+	testAccOtherSSHPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHq4ZRm1vPgxbDsxoJ2wXVtUFu8fN0Jq7bkQpLrS2cZY acctest@example.com"
+)
+
+func TestAccSSHKeyResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckSSHKeyDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccSSHKeyResourceConfig(name, testAccSSHPublicKey),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_sshkey.test", "metadata.name", name),
+					resource.TestCheckResourceAttr("intelcloud_sshkey.test", "spec.ssh_public_key", testAccSSHPublicKey),
+					resource.TestCheckResourceAttrSet("intelcloud_sshkey.test", "metadata.resourceid"),
+					resource.TestCheckResourceAttrSet("intelcloud_sshkey.test", "metadata.cloudaccount"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "intelcloud_sshkey.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccImportStateIDFunc("intelcloud_sshkey.test", "metadata.resourceid"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "metadata.resourceid",
+				ImportStateVerifyIgnore:              []string{"metadata.createdat"},
+			},
+			// Update testing, a new key replaces the old one
+			{
+				Config: testAccSSHKeyResourceConfig(name, testAccOtherSSHPublicKey),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_sshkey.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_sshkey.test", "spec.ssh_public_key", testAccOtherSSHPublicKey),
+			},
+			// Changing the timeouts updates the key in place
+			{
+				Config: testAccSSHKeyResourceConfigWithTimeouts(name, testAccOtherSSHPublicKey, "10m"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_sshkey.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_sshkey.test", "timeouts.create", "10m"),
+			},
+			// Drift testing, a key deleted outside of Terraform is recreated
+			{
+				Config:             testAccSSHKeyResourceConfig(name, testAccOtherSSHPublicKey),
+				Check:              testAccDeleteOutOfBand("intelcloud_sshkey.test", deleteSSHKey),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccSSHKeyResourceConfig(name, testAccOtherSSHPublicKey),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_sshkey.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccSSHKeyResource_adoptExisting(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	var existingID string
+	adoptedKey := strings.TrimSuffix(testAccSSHPublicKey, "acctest@example.com") + "laptop\n"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckSSHKeyDestroy,
+		Steps: []resource.TestStep{
+			// A key with the name exists, the create fails without adopt_existing
+			{
+				PreConfig: func() {
+					client, err := testAccAPIClient()
+					if err != nil {
+						t.Fatal(err)
+					}
+					in := &itacservices.SSHKeyCreateRequest{}
+					in.Metadata.Name = name
+					in.Spec.SSHPublicKey = testAccSSHPublicKey
+					in.Spec.OwnerEmail = "acctest@example.com"
+					sshkey, err := client.CreateSSHkey(context.Background(), in)
+					if err != nil {
+						t.Fatal(err)
+					}
+					existingID = sshkey.Metadata.ResourceId
+				},
+				Config:      testAccSSHKeyResourceConfig(name, testAccSSHPublicKey),
+				ExpectError: regexp.MustCompile(`already used\s+by\s+another\s+sshkey`),
+			},
+			// A key that does not match the configuration is not adopted
+			{
+				Config:      testAccSSHKeyResourceConfigAdopt(name, testAccOtherSSHPublicKey),
+				ExpectError: regexp.MustCompile(`does\s+not\s+match\s+the\s+configuration`),
+			},
+			// The same key with another comment is adopted
+			{
+				Config: testAccSSHKeyResourceConfigAdopt(name, adoptedKey),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("intelcloud_sshkey.test", "metadata.resourceid", func(id string) error {
+						if id != existingID {
+							return fmt.Errorf("resource id = %s, want the existing key %s", id, existingID)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttr("intelcloud_sshkey.test", "adopt_existing", "true"),
+				),
+			},
+			// Turning adopt_existing off updates the key in place
+			{
+				Config: testAccSSHKeyResourceConfig(name, adoptedKey),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_sshkey.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckNoResourceAttr("intelcloud_sshkey.test", "adopt_existing"),
+			},
+		},
+	})
+}
+
+func testAccSSHKeyResourceConfig(name, publicKey string) string {
+	return fmt.Sprintf(`
+resource "intelcloud_sshkey" "test" {
+  metadata = {
+    name = %[1]q
+  }
+  spec = {
+    ssh_public_key = %[2]q
+    owner_email    = "acctest@example.com"
+  }
+}
+`, name, publicKey)
+}
+
+func testAccSSHKeyResourceConfigWithTimeouts(name, publicKey, timeout string) string {
+	return fmt.Sprintf(`
+resource "intelcloud_sshkey" "test" {
+  metadata = {
+    name = %[1]q
+  }
+  spec = {
+    ssh_public_key = %[2]q
+    owner_email    = "acctest@example.com"
+  }
+  timeouts {
+    create = %[3]q
+    delete = %[3]q
+  }
+}
+`, name, publicKey, timeout)
+}
+
+func testAccSSHKeyResourceConfigAdopt(name, publicKey string) string {
+	return fmt.Sprintf(`
+resource "intelcloud_sshkey" "test" {
+  metadata = {
+    name = %[1]q
+  }
+  spec = {
+    ssh_public_key = %[2]q
+    owner_email    = "acctest@example.com"
+  }
+  adopt_existing = true
+}
+`, name, publicKey)
+}
+
+func deleteSSHKey(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteSSHKeyByResourceId(ctx, attrs["metadata.resourceid"])
+}
+
+var testAccCheckSSHKeyDestroy = testAccCheckDestroyed("intelcloud_sshkey", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetSSHKeyByResourceId(ctx, attrs["metadata.resourceid"])
+	return err
+})
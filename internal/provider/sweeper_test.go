@@ -0,0 +1,292 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// Sweepers delete resources leaked by failed acceptance test runs. Run them
+// with
+//
+//	go test ./internal/provider -v -sweep=<region>
+//
+// They delete every resource whose name starts with ITAC_SWEEP_PREFIX,
+// tf-acc by default, the prefix of the names used by the tests.
+const defaultSweepPrefix = "tf-acc"
+
+// sweepTimeout bounds a sweeper. Deletes wait for the resource to be gone,
+// so resources are swept after those depending on them.
+const sweepTimeout = 30 * time.Minute
+
+func init() {
+	resource.AddTestSweepers("intelcloud_instance", &resource.Sweeper{
+		Name: "intelcloud_instance",
+		F:    sweepInstances,
+	})
+	resource.AddTestSweepers("intelcloud_sshkey", &resource.Sweeper{
+		Name:         "intelcloud_sshkey",
+		Dependencies: []string{"intelcloud_instance", "intelcloud_iks_node_group"},
+		F:            sweepSSHKeys,
+	})
+	resource.AddTestSweepers("intelcloud_vnet", &resource.Sweeper{
+		Name:         "intelcloud_vnet",
+		Dependencies: []string{"intelcloud_instance", "intelcloud_iks_node_group"},
+		F:            sweepVNets,
+	})
+	resource.AddTestSweepers("intelcloud_filesystem", &resource.Sweeper{
+		Name: "intelcloud_filesystem",
+		F:    sweepFilesystems,
+	})
+	resource.AddTestSweepers("intelcloud_object_storage_bucket_user", &resource.Sweeper{
+		Name: "intelcloud_object_storage_bucket_user",
+		F:    sweepObjectUsers,
+	})
+	resource.AddTestSweepers("intelcloud_object_storage_bucket", &resource.Sweeper{
+		Name:         "intelcloud_object_storage_bucket",
+		Dependencies: []string{"intelcloud_object_storage_bucket_user"},
+		F:            sweepObjectBuckets,
+	})
+	resource.AddTestSweepers("intelcloud_iks_lb", &resource.Sweeper{
+		Name: "intelcloud_iks_lb",
+		F:    sweepIKSLoadBalancers,
+	})
+	resource.AddTestSweepers("intelcloud_iks_node_group", &resource.Sweeper{
+		Name: "intelcloud_iks_node_group",
+		F:    sweepIKSNodeGroups,
+	})
+	resource.AddTestSweepers("intelcloud_iks_cluster", &resource.Sweeper{
+		Name:         "intelcloud_iks_cluster",
+		Dependencies: []string{"intelcloud_iks_lb", "intelcloud_iks_node_group"},
+		F:            sweepIKSClusters,
+	})
+}
+
+func sweepPrefix() string {
+	if prefix := os.Getenv("ITAC_SWEEP_PREFIX"); prefix != "" {
+		return prefix
+	}
+	return defaultSweepPrefix
+}
+
+func sweepable(name string) bool {
+	return strings.HasPrefix(name, sweepPrefix())
+}
+
+// sweepDelete deletes one resource, treating a resource that is already gone
+// as deleted.
+func sweepDelete(ctx context.Context, kind, name string, del func(ctx context.Context) error) error {
+	log.Printf("[INFO] sweeping %s %s", kind, name)
+	if err := del(ctx); err != nil && !common.IsNotFound(err) {
+		return fmt.Errorf("error sweeping %s %s: %w", kind, name, err)
+	}
+	return nil
+}
+
+// sweep runs f with a client for region, bounded by sweepTimeout.
+func sweep(region string, f func(ctx context.Context, client *itacservices.IDCServicesClient) error) error {
+	client, err := newTestAPIClient(region)
+	if err != nil {
+		return fmt.Errorf("error creating client for region %s: %w", region, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sweepTimeout)
+	defer cancel()
+	return f(ctx, client)
+}
+
+func sweepInstances(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		instances, err := client.GetInstances(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing instances: %w", err)
+		}
+		var errs []error
+		for _, instance := range instances.Instances {
+			if !sweepable(instance.Metadata.Name) {
+				continue
+			}
+			id := instance.Metadata.ResourceId
+			errs = append(errs, sweepDelete(ctx, "instance", instance.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteInstanceByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepSSHKeys(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		keys, err := client.GetSSHKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing ssh keys: %w", err)
+		}
+		var errs []error
+		for _, key := range keys.SSHKey {
+			if !sweepable(key.Metadata.Name) {
+				continue
+			}
+			id := key.Metadata.ResourceId
+			errs = append(errs, sweepDelete(ctx, "ssh key", key.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteSSHKeyByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepVNets(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		vnets, err := client.GetVNets(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing vnets: %w", err)
+		}
+		var errs []error
+		for _, vnet := range vnets.Vnets {
+			if !sweepable(vnet.Metadata.Name) {
+				continue
+			}
+			id := vnet.Metadata.ResourceId
+			errs = append(errs, sweepDelete(ctx, "vnet", vnet.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteVNetByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepFilesystems(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		filesystems, err := client.GetFilesystems(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing filesystems: %w", err)
+		}
+		var errs []error
+		for _, fs := range filesystems.FilesystemList {
+			if !sweepable(fs.Metadata.Name) {
+				continue
+			}
+			id := fs.Metadata.ResourceId
+			errs = append(errs, sweepDelete(ctx, "filesystem", fs.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteFilesystemByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepObjectUsers(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		users, err := client.GetObjectUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing object users: %w", err)
+		}
+		var errs []error
+		for _, user := range users.Users {
+			if !sweepable(user.Metadata.Name) {
+				continue
+			}
+			id := user.Metadata.UserId
+			errs = append(errs, sweepDelete(ctx, "object user", user.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteObjectUserByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepObjectBuckets(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		buckets, err := client.GetObjectBuckets(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing object buckets: %w", err)
+		}
+		var errs []error
+		for _, bucket := range buckets.Buckets {
+			// The service prefixes bucket names with the cloud account.
+			name := strings.TrimPrefix(bucket.Metadata.Name, *client.Cloudaccount+"-")
+			if !sweepable(name) {
+				continue
+			}
+			id := bucket.Metadata.ResourceId
+			errs = append(errs, sweepDelete(ctx, "object bucket", bucket.Metadata.Name, func(ctx context.Context) error {
+				return client.DeleteBucketByResourceId(ctx, id)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepIKSLoadBalancers(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		clusters, _, err := client.GetKubernetesClusters(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing iks clusters: %w", err)
+		}
+		var errs []error
+		for _, cluster := range clusters.Clusters {
+			clusterUUID := cluster.ResourceId
+			for _, vip := range cluster.VIPs {
+				if !sweepable(cluster.Name) && !sweepable(vip.Name) {
+					continue
+				}
+				vipId := vip.Id
+				errs = append(errs, sweepDelete(ctx, "iks load balancer", vip.Name, func(ctx context.Context) error {
+					return client.DeleteIKSLoadBalancer(ctx, clusterUUID, vipId)
+				}))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepIKSNodeGroups(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		clusters, _, err := client.GetKubernetesClusters(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing iks clusters: %w", err)
+		}
+		var errs []error
+		for _, cluster := range clusters.Clusters {
+			clusterUUID := cluster.ResourceId
+			for _, ng := range cluster.NodeGroups {
+				if !sweepable(cluster.Name) && !sweepable(ng.Name) {
+					continue
+				}
+				ngId := ng.ID
+				errs = append(errs, sweepDelete(ctx, "iks node group", ng.Name, func(ctx context.Context) error {
+					return client.DeleteIKSNodeGroup(ctx, clusterUUID, ngId)
+				}))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+func sweepIKSClusters(region string) error {
+	return sweep(region, func(ctx context.Context, client *itacservices.IDCServicesClient) error {
+		clusters, _, err := client.GetKubernetesClusters(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing iks clusters: %w", err)
+		}
+		var errs []error
+		for _, cluster := range clusters.Clusters {
+			if !sweepable(cluster.Name) {
+				continue
+			}
+			clusterUUID := cluster.ResourceId
+			errs = append(errs, sweepDelete(ctx, "iks cluster", cluster.Name, func(ctx context.Context) error {
+				return client.DeleteIKSCluster(ctx, clusterUUID)
+			}))
+		}
+		return errors.Join(errs...)
+	})
+}
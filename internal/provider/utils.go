@@ -1,6 +1,27 @@
 package provider
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// defaultTimeout bounds the operations on resources that the API creates and
+// deletes synchronously.
+const defaultTimeout = 5 * time.Minute
 
 func remove(slice []interface{}, s int) []interface{} {
 	return append(slice[:s], slice[s+1:]...)
@@ -12,3 +33,171 @@ func capitalize(s string) string {
 	}
 	return strings.ToUpper(s[:1]) + s[1:]
 }
+
+// optionalString returns s as a string value, or null when the API left an
+// optional field unset.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+// updateTimeouts is the Update of resources whose configurable attributes
+// all require replacement, where only the timeouts can change in place.
+func updateTimeouts(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var t timeouts.Value
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("timeouts"), &t)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.State.Raw = req.State.Raw
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("timeouts"), t)...)
+}
+
+// updateAdoptExisting sets adopt_existing, which only matters to creates,
+// to its planned value after updateTimeouts.
+func updateAdoptExisting(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var adopt types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("adopt_existing"), &adopt)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt_existing"), adopt)...)
+}
+
+// addCreateError adds the error of a create call to diags. A resource that
+// failed to provision, or was not ready in time, is reported with the last
+// phase and message the API gave for it, which tell why, such as a quota or
+// capacity shortage.
+func addCreateError(diags *diag.Diagnostics, summary, detail string, err error) {
+	var provErr *common.ProvisioningError
+	if !errors.As(err, &provErr) {
+		diags.AddError(summary, detail+", unexpected error: "+err.Error())
+		return
+	}
+
+	var b strings.Builder
+	if provErr.Err == nil {
+		fmt.Fprintf(&b, "The %s %s failed to provision.", provErr.Kind, provErr.ResourceId)
+	} else {
+		fmt.Fprintf(&b, "The %s %s did not become ready: %v", provErr.Kind, provErr.ResourceId, provErr.Err)
+	}
+	if provErr.Phase != "" {
+		fmt.Fprintf(&b, "\n\nLast phase: %s", provErr.Phase)
+	}
+	if provErr.Message != "" {
+		fmt.Fprintf(&b, "\nMessage: %s", provErr.Message)
+	}
+	diags.AddError("Error provisioning "+provErr.Kind, b.String())
+}
+
+// nameTaken reports whether a create failed with err because the name is
+// already in use, exists telling whether a resource of that name exists.
+func nameTaken(ctx context.Context, err error, exists func(ctx context.Context) (bool, error)) bool {
+	var conflict *common.ConflictError
+	var invalid *common.ValidationError
+	if !errors.As(err, &conflict) && !errors.As(err, &invalid) {
+		return false
+	}
+	found, lookupErr := exists(ctx)
+	if lookupErr != nil {
+		tflog.Debug(ctx, "unable to look up the name of a rejected create", map[string]any{"error": lookupErr.Error()})
+		return false
+	}
+	return found
+}
+
+// addNameTakenError adds the error of a create that failed because another
+// kind is named name.
+func addNameTakenError(diags *diag.Diagnostics, namePath path.Path, kind, name string, err error) {
+	diags.AddAttributeError(namePath, "Error creating order",
+		fmt.Sprintf("Could not create order, the name %q is already used by another %s. "+
+			"Import it, or set adopt_existing to adopt it if it matches the configuration: %s", name, kind, err))
+}
+
+// privateCreating is the private state key marking a resource whose create
+// was interrupted before the resource was ready. The next apply resumes
+// waiting for it with an update instead of creating another one.
+const privateCreating = "creating"
+
+// addCreateFailure handles the error of a create call. A resource that was
+// created but is not ready is kept in the state with the id and status at
+// idPath and statusPath, so that it is not created twice: after an interrupt
+// it is marked with privateCreating and the next apply resumes waiting for
+// it, after any other error it is tainted and replaced by the next apply.
+func addCreateFailure(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse,
+	idPath, statusPath path.Path, summary, detail string, err error) {
+	var provErr *common.ProvisioningError
+	if !errors.As(err, &provErr) || provErr.ResourceId == "" {
+		addCreateError(&resp.Diagnostics, summary, detail, err)
+		return
+	}
+
+	// Values the API did not return yet stay null until the create resumes.
+	raw, transformErr := tftypes.Transform(req.Plan.Raw, func(_ *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		if !v.IsKnown() {
+			return tftypes.NewValue(v.Type(), nil), nil
+		}
+		return v, nil
+	})
+	if transformErr != nil {
+		addCreateError(&resp.Diagnostics, summary, detail, errors.Join(err, transformErr))
+		return
+	}
+	resp.State.Raw = raw
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, idPath, provErr.ResourceId)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, statusPath, optionalString(provErr.Phase))...)
+
+	if !errors.Is(err, context.Canceled) {
+		addCreateError(&resp.Diagnostics, summary, detail, err)
+		return
+	}
+	tflog.Warn(ctx, "create interrupted, saving the resource to resume it", map[string]any{"id": provErr.ResourceId})
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, privateCreating, []byte("true"))...)
+	resp.Diagnostics.AddWarning(
+		"Create interrupted",
+		fmt.Sprintf("Waiting for the %s %s to be ready was interrupted. The next apply resumes waiting for it.", provErr.Kind, provErr.ResourceId),
+	)
+}
+
+// resumingCreate reports whether the create of the resource was interrupted
+// and is still to be resumed.
+func resumingCreate(ctx context.Context, private interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}, diags *diag.Diagnostics) bool {
+	creating, d := private.GetKey(ctx, privateCreating)
+	diags.Append(d...)
+	return creating != nil
+}
+
+// planResumedCreate plans an update of a resource whose create was
+// interrupted, marking the computed attributes at paths as unknown so that
+// the update resuming the create can set them. Configured values are planned
+// as they are.
+func planResumedCreate(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, paths ...path.Path) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || !resumingCreate(ctx, req.Private, &resp.Diagnostics) {
+		return
+	}
+	for _, p := range paths {
+		var configured attr.Value
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, p, &configured)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if !configured.IsNull() {
+			continue
+		}
+		t, diags := resp.Plan.Schema.TypeAtPath(ctx, p)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		unknown, err := t.ValueFromTerraform(ctx, tftypes.NewValue(t.TerraformType(ctx), tftypes.UnknownValue))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(p, "Error planning resumed create", err.Error())
+			return
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, p, unknown)...)
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// initPrivateState sets the private state field at p to an empty private
+// state, which only the framework can create otherwise.
+func initPrivateState(p any) {
+	v := reflect.ValueOf(p).Elem()
+	v.Set(reflect.New(v.Type().Elem()))
+}
+
+func TestAddCreateFailure(t *testing.T) {
+	testSchema := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name":   schema.StringAttribute{Required: true},
+			"id":     schema.StringAttribute{Computed: true},
+			"status": schema.StringAttribute{Computed: true},
+		},
+	}
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"name":   tftypes.String,
+		"id":     tftypes.String,
+		"status": tftypes.String,
+	}}
+	plan := tftypes.NewValue(objectType, map[string]tftypes.Value{
+		"name":   tftypes.NewValue(tftypes.String, "test"),
+		"id":     tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+		"status": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		// wantState tells whether the resource is saved in the state,
+		// wantResume whether it is marked to resume its create.
+		wantState  bool
+		wantResume bool
+		wantError  bool
+	}{
+		{
+			name:       "interrupted while waiting",
+			err:        &common.ProvisioningError{Kind: "instance", ResourceId: "i-1", Phase: "Provisioning", Err: context.Canceled},
+			wantState:  true,
+			wantResume: true,
+		},
+		{
+			name:      "timed out while waiting",
+			err:       &common.ProvisioningError{Kind: "instance", ResourceId: "i-1", Phase: "Provisioning", Err: context.DeadlineExceeded},
+			wantState: true,
+			wantError: true,
+		},
+		{
+			name:      "failed to provision",
+			err:       &common.ProvisioningError{Kind: "instance", ResourceId: "i-1", Phase: "Failed", Message: "no capacity"},
+			wantState: true,
+			wantError: true,
+		},
+		{
+			name:      "interrupted before the create call returned",
+			err:       fmt.Errorf("error reading instance create response: %w", context.Canceled),
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			req := resource.CreateRequest{Plan: tfsdk.Plan{Schema: testSchema, Raw: plan}}
+			resp := &resource.CreateResponse{State: tfsdk.State{Schema: testSchema, Raw: tftypes.NewValue(objectType, nil)}}
+			initPrivateState(&resp.Private)
+
+			addCreateFailure(ctx, req, resp, path.Root("id"), path.Root("status"), "Error creating instance", "Could not create instance", tc.err)
+
+			if got := resp.Diagnostics.HasError(); got != tc.wantError {
+				t.Errorf("error reported = %v, want %v: %v", got, tc.wantError, resp.Diagnostics)
+			}
+			if tc.wantResume && resp.Diagnostics.WarningsCount() != 1 {
+				t.Errorf("warnings = %v, want the interrupted create reported", resp.Diagnostics.Warnings())
+			}
+
+			var diags diag.Diagnostics
+			if got := resumingCreate(ctx, resp.Private, &diags); got != tc.wantResume {
+				t.Errorf("resuming create = %v, want %v", got, tc.wantResume)
+			}
+
+			if !tc.wantState {
+				if !resp.State.Raw.IsNull() {
+					t.Errorf("state = %v, want none", resp.State.Raw)
+				}
+				return
+			}
+			var state struct {
+				Name   types.String `tfsdk:"name"`
+				ID     types.String `tfsdk:"id"`
+				Status types.String `tfsdk:"status"`
+			}
+			if d := resp.State.Get(ctx, &state); d.HasError() {
+				t.Fatalf("reading state: %v", d)
+			}
+			var provErr *common.ProvisioningError
+			errors.As(tc.err, &provErr)
+			if state.Name.ValueString() != "test" || state.ID.ValueString() != provErr.ResourceId || state.Status.ValueString() != provErr.Phase {
+				t.Errorf("state = %+v, want the planned name with the id and phase of the resource", state)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &vnetResource{}
+	_ resource.ResourceWithConfigure   = &vnetResource{}
+	_ resource.ResourceWithImportState = &vnetResource{}
+)
+
+// vnetResourceModel maps the resource schema data.
+type vnetResourceModel struct {
+	ID               types.String   `tfsdk:"id"`
+	Cloudaccount     types.String   `tfsdk:"cloudaccount"`
+	Name             types.String   `tfsdk:"name"`
+	AvailabilityZone types.String   `tfsdk:"availability_zone"`
+	Region           types.String   `tfsdk:"region"`
+	PrefixLength     types.Int64    `tfsdk:"prefix_length"`
+	Timeouts         timeouts.Value `tfsdk:"timeouts"`
+}
+
+// NewVNetResource is a helper function to simplify the provider implementation.
+func NewVNetResource() resource.Resource {
+	return &vnetResource{}
+}
+
+// vnetResource is the resource implementation.
+type vnetResource struct {
+	client *itacservices.IDCServicesClient
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *vnetResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*itacservices.IDCServicesClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *itacservices.IDCServicesClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// Metadata returns the resource type name.
+func (r *vnetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vnet"
+}
+
+// Schema defines the schema for the resource.
+func (r *vnetResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cloudaccount": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"availability_zone": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The availability zone of the vnet. Defaults to the first zone of the region, such as us-region-1a.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"region": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"prefix_length": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(itacservices.DefaultVNetPrefixLength),
+				Description: "The prefix length of the subnet of the vnet.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+// Create creates the resource and sets the initial Terraform state.
+func (r *vnetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan vnetResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	inArg := itacservices.VNetCreateRequest{}
+	inArg.Metadata.Name = plan.Name.ValueString()
+	inArg.Spec.AvailabilityZone = plan.AvailabilityZone.ValueString()
+	if plan.AvailabilityZone.IsUnknown() {
+		inArg.Spec.AvailabilityZone = r.client.DefaultAvailabilityZone()
+	}
+	inArg.Spec.Region = *r.client.Region
+	inArg.Spec.PrefixLength = plan.PrefixLength.ValueInt64()
+
+	tflog.Info(ctx, "making a call to IDC Service for create vnet")
+	vnet, err := r.client.CreateVNet(ctx, &inArg)
+	if err != nil {
+		if nameTaken(ctx, err, func(ctx context.Context) (bool, error) {
+			existing, err := r.client.GetVNetByName(ctx, inArg.Metadata.Name)
+			return existing != nil, err
+		}) {
+			resp.Diagnostics.AddAttributeError(path.Root("name"), "Error creating vnet",
+				fmt.Sprintf("Could not create vnet, the name %q is already used by another vnet. Import it to manage it: %s", inArg.Metadata.Name, err))
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error creating vnet",
+			"Could not create vnet, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	refreshVNetResourceModel(&plan, vnet)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *vnetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state vnetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get refreshed vnet value from IDC Service
+	vnet, err := r.client.GetVNetByResourceId(ctx, state.ID.ValueString())
+	if err != nil {
+		if common.IsNotFound(err) {
+			tflog.Warn(ctx, "vnet not found, removing it from state", map[string]any{"id": state.ID.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading IDC VNet resource",
+			"Could not read IDC VNet resource ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	refreshVNetResourceModel(&state, vnet)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update only changes the timeouts, every other attribute of a vnet
+// requires its replacement.
+func (r *vnetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	updateTimeouts(ctx, req, resp)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *vnetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Get current state
+	var state vnetResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	// Delete the vnet from IDC Services
+	err := r.client.DeleteVNetByResourceId(ctx, state.ID.ValueString())
+	if err != nil && !common.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Error Deleting IDC VNet resource",
+			"Could not delete IDC VNet resource ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *vnetResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to id attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// refreshVNetResourceModel sets the attributes of model that the API reports.
+func refreshVNetResourceModel(model *vnetResourceModel, vnet *itacservices.VNet) {
+	model.ID = types.StringValue(vnet.Metadata.ResourceId)
+	model.Cloudaccount = types.StringValue(vnet.Metadata.Cloudaccount)
+	model.Name = types.StringValue(vnet.Metadata.Name)
+	model.AvailabilityZone = types.StringValue(vnet.Spec.AvailabilityZone)
+	model.Region = types.StringValue(vnet.Spec.Region)
+	model.PrefixLength = types.Int64Value(vnet.Spec.PrefixLength)
+}
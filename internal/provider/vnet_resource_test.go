@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestAccVNetResource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	region := os.Getenv("ITAC_REGION")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVNetDestroy,
+		Steps: []resource.TestStep{
+			// Create and Read testing, in the default zone of the region
+			{
+				Config: testAccVNetResourceConfig(name, 24),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("intelcloud_vnet.test", "name", name),
+					resource.TestCheckResourceAttr("intelcloud_vnet.test", "availability_zone", region+"a"),
+					resource.TestCheckResourceAttr("intelcloud_vnet.test", "region", region),
+					resource.TestCheckResourceAttr("intelcloud_vnet.test", "prefix_length", "24"),
+					resource.TestCheckResourceAttrSet("intelcloud_vnet.test", "id"),
+					resource.TestCheckResourceAttrSet("intelcloud_vnet.test", "cloudaccount"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "intelcloud_vnet.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"timeouts"},
+			},
+			// Update testing, a new prefix length replaces the vnet
+			{
+				Config: testAccVNetResourceConfig(name, 22),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_vnet.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("intelcloud_vnet.test", "prefix_length", "22"),
+			},
+			// Drift testing, a vnet deleted outside of Terraform is recreated
+			{
+				Config:             testAccVNetResourceConfig(name, 22),
+				Check:              testAccDeleteOutOfBand("intelcloud_vnet.test", deleteVNet),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccVNetResourceConfig(name, 22),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("intelcloud_vnet.test", plancheck.ResourceActionCreate),
+					},
+				},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccVNetResource_nameTaken(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckVNetDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVNetResourceConfig(name, 24) + fmt.Sprintf(`
+resource "intelcloud_vnet" "other" {
+  name       = %[1]q
+  depends_on = [intelcloud_vnet.test]
+}
+`, name),
+				ExpectError: regexp.MustCompile(`already used\s+by\s+another\s+vnet`),
+			},
+		},
+	})
+}
+
+func testAccVNetResourceConfig(name string, prefixLength int) string {
+	return fmt.Sprintf(`
+resource "intelcloud_vnet" "test" {
+  name          = %[1]q
+  prefix_length = %[2]d
+}
+`, name, prefixLength)
+}
+
+func deleteVNet(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	return client.DeleteVNetByResourceId(ctx, attrs["id"])
+}
+
+var testAccCheckVNetDestroy = testAccCheckDestroyed("intelcloud_vnet", func(ctx context.Context, client *itacservices.IDCServicesClient, attrs map[string]string) error {
+	_, err := client.GetVNetByResourceId(ctx, attrs["id"])
+	return err
+})
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func NewVNetsDataSource() datasource.DataSource {
+	return &vnetsDataSource{}
+}
+
+type vnetsDataSource struct {
+	client *itacservices.IDCServicesClient
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &vnetsDataSource{}
+	_ datasource.DataSourceWithConfigure = &vnetsDataSource{}
+)
+
+// vnetsDataSourceModel maps the data source schema data.
+type vnetsDataSourceModel struct {
+	AvailabilityZone types.String `tfsdk:"availability_zone"`
+	VNets            []vnetModel  `tfsdk:"vnets"`
+}
+
+// vnetModel maps vnets schema data.
+type vnetModel struct {
+	ID               types.String `tfsdk:"id"`
+	Cloudaccount     types.String `tfsdk:"cloudaccount"`
+	Name             types.String `tfsdk:"name"`
+	AvailabilityZone types.String `tfsdk:"availability_zone"`
+	Region           types.String `tfsdk:"region"`
+	PrefixLength     types.Int64  `tfsdk:"prefix_length"`
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *vnetsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*itacservices.IDCServicesClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *itacservices.IDCServicesClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *vnetsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vnets"
+}
+
+func (d *vnetsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"availability_zone": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only list the vnets of this availability zone.",
+			},
+			"vnets": schema.ListNestedAttribute{
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"cloudaccount": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"availability_zone": schema.StringAttribute{
+							Computed: true,
+						},
+						"region": schema.StringAttribute{
+							Computed: true,
+						},
+						"prefix_length": schema.Int64Attribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *vnetsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state vnetsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.VNets = []vnetModel{}
+
+	vnetList, err := d.client.GetVNets(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read IDC Compute VNets",
+			err.Error(),
+		)
+		return
+	}
+	for _, vnet := range vnetList.Vnets {
+		if zone := state.AvailabilityZone.ValueString(); zone != "" && vnet.Spec.AvailabilityZone != zone {
+			continue
+		}
+		state.VNets = append(state.VNets, vnetModel{
+			ID:               types.StringValue(vnet.Metadata.ResourceId),
+			Cloudaccount:     types.StringValue(vnet.Metadata.Cloudaccount),
+			Name:             types.StringValue(vnet.Metadata.Name),
+			AvailabilityZone: types.StringValue(vnet.Spec.AvailabilityZone),
+			Region:           types.StringValue(vnet.Spec.Region),
+			PrefixLength:     types.Int64Value(vnet.Spec.PrefixLength),
+		})
+	}
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,35 @@
+package provider
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccVNetsDataSource(t *testing.T) {
+	name := acctest.RandomWithPrefix("tf-acc")
+	region := os.Getenv("ITAC_REGION")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVNetResourceConfig(name, 24) + `
+data "intelcloud_vnets" "test" {
+  availability_zone = intelcloud_vnet.test.availability_zone
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckTypeSetElemNestedAttrs("data.intelcloud_vnets.test", "vnets.*", map[string]string{
+						"name":              name,
+						"availability_zone": region + "a",
+						"prefix_length":     "24",
+					}),
+				),
+			},
+		},
+	})
+}
@@ -5,15 +5,19 @@ import (
 	"context"
 	b64 "encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
+	"slices"
+	"strings"
+	"sync"
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	retry "github.com/sethvargo/go-retry"
 )
 
 type IDCServicesClient struct {
@@ -24,26 +28,314 @@ type IDCServicesClient struct {
 	Clientid     *string
 	Clientsecret *string
 	ExpireAt     time.Time
+	// RetryPolicy overrides common.DefaultRetryPolicy for API calls.
+	RetryPolicy *common.RetryPolicy
+	// HTTPClient is shared by all API and token calls of the client.
+	HTTPClient *http.Client
+
+	tokenSvc    *string
+	tokenSource TokenSource
+	// mu guards Apitoken and ExpireAt so that concurrent resource operations
+	// share a single token refresh.
+	mu sync.Mutex
 }
 
 var (
 	getTokenURL = "{{.Host}}/oauth2/token"
 )
 
+// tokenRefreshWindow is how long before its expiry an access token is
+// considered stale and exchanged for a new one.
+const tokenRefreshWindow = 60 * time.Second
+
+// pollInterval is the wait between two reads of a resource whose phase is
+// being awaited.
+var pollInterval = 5 * time.Second
+
+// Default bounds of the loops waiting for a resource to be ready, used when
+// the context of the call has no deadline.
+const (
+	DefaultInstanceTimeout        = 300 * time.Second
+	DefaultFilesystemTimeout      = 300 * time.Second
+	DefaultBucketTimeout          = 300 * time.Second
+	DefaultIKSClusterTimeout      = 1800 * time.Second
+	DefaultIKSNodeGroupTimeout    = 3000 * time.Second
+	DefaultIKSStorageTimeout      = 3000 * time.Second
+	DefaultIKSLoadBalancerTimeout = 3000 * time.Second
+)
+
+// waitBackoff returns the backoff of a wait loop. It polls until the
+// deadline of ctx, or for at most defaultTimeout when ctx has none.
+func waitBackoff(ctx context.Context, defaultTimeout time.Duration) retry.Backoff {
+	backoffTimer := retry.NewConstant(pollInterval)
+	if _, ok := ctx.Deadline(); ok {
+		return backoffTimer
+	}
+	return retry.WithMaxDuration(defaultTimeout, backoffTimer)
+}
+
+// provisioningError returns the error ending the wait for a resource of kind
+// to become ready, given the phase and message it was last read with. A
+// failure already reported by the wait loop is returned as is.
+func provisioningError(kind, resourceId, phase, message string, err error) error {
+	var failed *common.ProvisioningError
+	if errors.As(err, &failed) {
+		return err
+	}
+	return &common.ProvisioningError{Kind: kind, ResourceId: resourceId, Phase: phase, Message: message, Err: err}
+}
+
+// waitForDeletion polls phase until the resource of the given kind is gone,
+// that is until reading it returns a not found error or one of the deleted
+// phases. Resources depending on it can only be deleted afterwards.
+func waitForDeletion(ctx context.Context, kind string, defaultTimeout time.Duration,
+	phase func(ctx context.Context) (string, error), deletedPhases ...string) error {
+	backoffTimer := waitBackoff(ctx, defaultTimeout)
+
+	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
+		current, err := phase(ctx)
+		if common.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %s state: %w", kind, err)
+		}
+		if slices.Contains(deletedPhases, current) {
+			return nil
+		}
+		return retry.RetryableError(fmt.Errorf("%s in phase %q, not deleted yet", kind, current))
+	}); err != nil {
+		return fmt.Errorf("%s not deleted after maximum retries: %w", kind, err)
+	}
+	tflog.Debug(ctx, "resource deleted", map[string]any{"kind": kind})
+	return nil
+}
+
 type TokenResponse struct {
 	AccessToken string `json:"access_token"`
 	TokenType   string `json:"token_type"`
 	ExpiresIn   int    `json:"expires_in"`
 }
 
+// TokenSource obtains a bearer token and its expiry from an external source.
+// A zero expiry means the expiry is taken from the token's JWT claims.
+type TokenSource func(ctx context.Context) (string, time.Time, error)
+
+// apiCall is the signature shared by the common.Make*APICall helpers.
+type apiCall func(ctx context.Context, connURL, auth string, payload []byte) (int, []byte, error)
+
+// NewClient creates a client that exchanges the client id and secret for
+// access tokens at tokenSvc. The client uses the HTTP client attached to ctx
+// with common.WithHTTPClient for all its calls.
 func NewClient(ctx context.Context, host, tokenSvc, cloudaccount, clientid, clientsecret, region *string) (*IDCServicesClient, error) {
-	os.Setenv("NO_PROXY", "")
-	os.Setenv("no_proxy", "")
+	client := &IDCServicesClient{
+		Host:         host,
+		Cloudaccount: cloudaccount,
+		Clientid:     clientid,
+		Clientsecret: clientsecret,
+		Region:       region,
+		tokenSvc:     tokenSvc,
+		HTTPClient:   common.HTTPClientFromContext(ctx),
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if err := client.refreshToken(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewClientWithToken creates a client that authenticates with a bearer token
+// minted elsewhere instead of exchanging client credentials. The token expiry
+// is taken from its JWT claims, opaque tokens and tokens without an exp claim
+// are used until the API rejects them; such a client cannot refresh the token.
+func NewClientWithToken(ctx context.Context, host, cloudaccount, apitoken, region *string) (*IDCServicesClient, error) {
+	expireAt := parseTokenExpiry(*apitoken)
+	if !expireAt.IsZero() && time.Now().After(expireAt) {
+		return nil, fmt.Errorf("api token expired at %s", expireAt.Format(time.RFC3339))
+	}
+	tflog.Info(ctx, "using api token", map[string]interface{}{"expireAt": expireAt})
 
+	return &IDCServicesClient{
+		Host:         host,
+		Cloudaccount: cloudaccount,
+		Region:       region,
+		Apitoken:     apitoken,
+		ExpireAt:     expireAt,
+		HTTPClient:   common.HTTPClientFromContext(ctx),
+	}, nil
+}
+
+// NewClientWithTokenSource creates a client that obtains its bearer tokens
+// from source, asking it for a new token whenever the current one is close to
+// expiry or rejected by the API. A token already obtained from source can be
+// passed with its expiry so that source is not asked again until then; source
+// is called right away when token is empty.
+func NewClientWithTokenSource(ctx context.Context, host, cloudaccount, region *string, token string, expireAt time.Time, source TokenSource) (*IDCServicesClient, error) {
+	client := &IDCServicesClient{
+		Host:         host,
+		Cloudaccount: cloudaccount,
+		Region:       region,
+		tokenSource:  source,
+		HTTPClient:   common.HTTPClientFromContext(ctx),
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if token != "" {
+		client.setSourcedToken(token, expireAt)
+		return client, nil
+	}
+	if err := client.refreshToken(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// parseTokenExpiry reads the exp claim of a JWT. A zero time is returned for
+// opaque tokens and tokens without an exp claim, whose expiry is unknown.
+func parseTokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := b64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// canRefresh reports whether the client holds credentials to obtain a new
+// access token on its own.
+func (client *IDCServicesClient) canRefresh() bool {
+	return client.tokenSource != nil ||
+		(client.tokenSvc != nil && client.Clientid != nil && client.Clientsecret != nil)
+}
+
+// refreshToken obtains a new access token from the token source or by
+// exchanging the client credentials. The caller must hold client.mu.
+func (client *IDCServicesClient) refreshToken(ctx context.Context) error {
+	if !client.canRefresh() {
+		if client.ExpireAt.IsZero() {
+			return fmt.Errorf("api token was rejected and cannot be refreshed")
+		}
+		return fmt.Errorf("api token expired at %s and cannot be refreshed", client.ExpireAt.Format(time.RFC3339))
+	}
+
+	if client.tokenSource != nil {
+		token, expireAt, err := client.tokenSource(ctx)
+		if err != nil {
+			return fmt.Errorf("error obtaining api token: %v", err)
+		}
+		client.setSourcedToken(token, expireAt)
+		return nil
+	}
+
+	tokenResp, err := getToken(ctx, client.HTTPClient, *client.tokenSvc, *client.Clientid, *client.Clientsecret)
+	if err != nil {
+		return err
+	}
+	client.Apitoken = &tokenResp.AccessToken
+	client.ExpireAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return nil
+}
+
+// setSourcedToken stores a token obtained from the token source. A zero
+// expireAt is taken from the token's exp claim, tokens without one are
+// treated as non-expiring. The caller must hold client.mu.
+func (client *IDCServicesClient) setSourcedToken(token string, expireAt time.Time) {
+	if expireAt.IsZero() {
+		expireAt = parseTokenExpiry(token)
+	}
+	client.Apitoken = &token
+	client.ExpireAt = expireAt
+}
+
+// accessToken returns a bearer token that is valid for at least
+// tokenRefreshWindow, refreshing it first if it is close to expiry.
+func (client *IDCServicesClient) accessToken(ctx context.Context) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	// Tokens without a known expiry are used until the API rejects them.
+	if client.Apitoken != nil && (client.ExpireAt.IsZero() || time.Until(client.ExpireAt) > tokenRefreshWindow) {
+		return *client.Apitoken, nil
+	}
+
+	// A token supplied by the user is used until it actually expires since
+	// it cannot be replaced ahead of time.
+	if !client.canRefresh() && client.Apitoken != nil && time.Now().Before(client.ExpireAt) {
+		return *client.Apitoken, nil
+	}
+
+	tflog.Debug(ctx, "access token expired or about to expire, refreshing", map[string]any{"expireAt": client.ExpireAt})
+	if err := client.refreshToken(ctx); err != nil {
+		return "", err
+	}
+	return *client.Apitoken, nil
+}
+
+// renewToken forces a refresh of a token rejected by the API. If another
+// caller has already replaced the rejected token, the current one is returned
+// without a new exchange.
+func (client *IDCServicesClient) renewToken(ctx context.Context, rejected string) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.Apitoken != nil && *client.Apitoken != rejected {
+		return *client.Apitoken, nil
+	}
+
+	tflog.Debug(ctx, "access token rejected by api, refreshing")
+	if err := client.refreshToken(ctx); err != nil {
+		return "", err
+	}
+	return *client.Apitoken, nil
+}
+
+// callAPI invokes call with a current bearer token. If the API answers 401
+// the token is renewed and the call is made once more.
+func (client *IDCServicesClient) callAPI(ctx context.Context, call apiCall, connURL string, payload []byte) (int, []byte, error) {
+	if client.RetryPolicy != nil {
+		ctx = common.WithRetryPolicy(ctx, *client.RetryPolicy)
+	}
+	if client.HTTPClient != nil {
+		ctx = common.WithHTTPClient(ctx, client.HTTPClient)
+	}
+
+	token, err := client.accessToken(ctx)
+	if err != nil {
+		return http.StatusUnauthorized, nil, err
+	}
+
+	retcode, retval, callErr := call(ctx, connURL, token, payload)
+	if retcode != http.StatusUnauthorized {
+		return retcode, retval, callErr
+	}
+
+	token, err = client.renewToken(ctx, token)
+	if err != nil {
+		tflog.Warn(ctx, "unable to renew rejected access token", map[string]any{"error": err})
+		return retcode, retval, callErr
+	}
+	return call(ctx, connURL, token, payload)
+}
+
+func getToken(ctx context.Context, httpClient *http.Client, tokenSvc, clientid, clientsecret string) (*TokenResponse, error) {
 	params := struct {
 		Host string
 	}{
-		Host: *tokenSvc,
+		Host: tokenSvc,
 	}
 
 	// Parse the template string with the provided data
@@ -54,24 +346,27 @@ func NewClient(ctx context.Context, host, tokenSvc, cloudaccount, clientid, clie
 
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
-	data.Set("client_id", *clientid)
+	data.Set("client_id", clientid)
 
-	req, err := http.NewRequest("POST", parsedURL, bytes.NewBufferString(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", parsedURL, bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("error creating ITAC Token request")
 	}
 
-	authStr := fmt.Sprintf("%s:%s", *clientid, *clientsecret)
+	authStr := fmt.Sprintf("%s:%s", clientid, clientsecret)
 	authEncoded := fmt.Sprintf("Basic %s", b64.StdEncoding.EncodeToString([]byte(authStr)))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
 	req.Header.Set("Authorization", authEncoded)
-	client := &http.Client{Timeout: 60 * time.Second}
+	if httpClient == nil {
+		httpClient = common.HTTPClientFromContext(ctx)
+	}
 
-	tflog.Info(ctx, "making api client request", map[string]interface{}{"request": req.Header, "url": parsedURL, "auth": authEncoded})
+	ctx = common.MaskSecrets(ctx)
+	tflog.Debug(ctx, "making token request", map[string]interface{}{"url": parsedURL})
 
-	resp, err := client.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		tflog.Info(ctx, "error making api client request", map[string]interface{}{"error": err})
 		return nil, fmt.Errorf("error creating ITAC Token request")
@@ -81,7 +376,8 @@ func NewClient(ctx context.Context, host, tokenSvc, cloudaccount, clientid, clie
 	retcode := resp.StatusCode
 	tokenResp := TokenResponse{}
 	if retcode != http.StatusOK {
-		tflog.Info(ctx, "error making api client request", map[string]interface{}{"retcode": retcode, "body": string(body)})
+		tflog.Info(ctx, "error making api client request", map[string]interface{}{"retcode": retcode})
+		tflog.Trace(ctx, "token response details", map[string]interface{}{"body": string(body)})
 		return nil, fmt.Errorf("error creating ITAC Token request")
 	}
 
@@ -90,14 +386,6 @@ func NewClient(ctx context.Context, host, tokenSvc, cloudaccount, clientid, clie
 		return nil, fmt.Errorf("error creating ITAC Token request")
 	}
 
-	tflog.Info(ctx, "Token Response", map[string]interface{}{"token": tokenResp.AccessToken, "expires_in": tokenResp.ExpiresIn})
-	return &IDCServicesClient{
-		Host:         host,
-		Cloudaccount: cloudaccount,
-		Clientid:     clientid,
-		Clientsecret: clientsecret,
-		Region:       region,
-		Apitoken:     &tokenResp.AccessToken,
-		ExpireAt:     time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second),
-	}, nil
+	tflog.Debug(ctx, "token response", map[string]interface{}{"expires_in": tokenResp.ExpiresIn})
+	return &tokenResp, nil
 }
@@ -0,0 +1,255 @@
+package itacservices
+
+import (
+	"bytes"
+	"context"
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+)
+
+const (
+	testCloudaccount = "123456789012"
+	// testToken is an unsigned JWT without expiry.
+	testToken = "eyJhbGciOiJub25lIn0.e30.c2ln"
+)
+
+// apiStep is a request the client is expected to make and the response it
+// gets.
+type apiStep struct {
+	method string
+	path   string
+	// body, if set, is the JSON payload the request must carry.
+	body string
+
+	status   int
+	response string
+}
+
+// apiScript serves the steps in order and fails the test on any request
+// that does not match the next step. The last step is repeated while the
+// client keeps polling with it.
+type apiScript struct {
+	t     *testing.T
+	steps []apiStep
+
+	mu   sync.Mutex
+	next int
+}
+
+func (s *apiScript) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		s.t.Errorf("%s %s: Authorization = %q", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+	}
+
+	step := s.steps[len(s.steps)-1]
+	if s.next < len(s.steps) {
+		step = s.steps[s.next]
+		s.next++
+	} else if step.method == http.MethodPost {
+		s.t.Errorf("unexpected request %s %s after the script ended", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method != step.method || r.URL.Path != step.path {
+		s.t.Errorf("request %d: got %s %s, want %s %s", s.next, r.Method, r.URL.Path, step.method, step.path)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if step.body != "" {
+		got, _ := io.ReadAll(r.Body)
+		if !jsonEqual(got, []byte(step.body)) {
+			s.t.Errorf("%s %s: payload = %s, want %s", r.Method, r.URL.Path, got, step.body)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(step.status)
+	_, _ = w.Write([]byte(step.response))
+}
+
+// done fails the test if some steps were not requested.
+func (s *apiScript) done() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next < len(s.steps) {
+		s.t.Errorf("%d of %d requests made, next expected %s %s",
+			s.next, len(s.steps), s.steps[s.next].method, s.steps[s.next].path)
+	}
+}
+
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return bytes.Equal(a, b)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
+
+// newScriptedClient returns a client for region talking to a server that
+// plays steps. The client polls every millisecond and does not retry.
+func newScriptedClient(t *testing.T, region string, steps []apiStep) (*IDCServicesClient, *apiScript) {
+	t.Helper()
+
+	saved := pollInterval
+	pollInterval = time.Millisecond
+	t.Cleanup(func() { pollInterval = saved })
+
+	script := &apiScript{t: t, steps: steps}
+	srv := httptest.NewServer(script)
+	t.Cleanup(srv.Close)
+
+	host, account, token := srv.URL, testCloudaccount, testToken
+	client, err := NewClientWithToken(context.Background(), &host, &account, &token, &region)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	client.RetryPolicy = &common.RetryPolicy{MaxRetries: 0, MinWait: time.Millisecond, MaxWait: time.Millisecond}
+	return client, script
+}
+
+// errorBody is an error response of the API.
+func errorBody(code int, message string) string {
+	b, _ := json.Marshal(map[string]any{"code": code, "message": message})
+	return string(b)
+}
+
+// isError reports whether err wraps an error of type T.
+func isError[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
+
+// isDeadlineExceeded reports whether err comes from the context deadline
+// running out.
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// anyError accepts every non-nil error.
+func anyError(err error) bool {
+	return err != nil
+}
+
+// jwt returns an unsigned JWT with the claims.
+func jwt(claims string) string {
+	return "eyJhbGciOiJub25lIn0." + b64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+}
+
+func TestNewClientWithToken(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name         string
+		token        string
+		wantExpireAt time.Time
+		wantErr      bool
+	}{
+		{name: "jwt with expiry", token: jwt(fmt.Sprintf(`{"exp": %d}`, exp.Unix())), wantExpireAt: exp},
+		{name: "jwt without expiry", token: testToken},
+		{name: "expired jwt", token: jwt(`{"exp": 1700000000}`), wantErr: true},
+		{name: "opaque token", token: "opaque-token"},
+		{name: "token with invalid claims", token: "a.b.c"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, account, region := "https://api.example.com", testCloudaccount, "us-region-1"
+			client, err := NewClientWithToken(context.Background(), &host, &account, &tc.token, &region)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("NewClientWithToken() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClientWithToken() error = %v", err)
+			}
+			if !client.ExpireAt.Equal(tc.wantExpireAt) {
+				t.Errorf("ExpireAt = %s, want %s", client.ExpireAt, tc.wantExpireAt)
+			}
+			token, err := client.accessToken(context.Background())
+			if err != nil || token != tc.token {
+				t.Errorf("accessToken() = %q, %v, want %q", token, err, tc.token)
+			}
+		})
+	}
+}
+
+func TestNewClientWithTokenSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expireAt time.Time
+		// wantCalls is how often the source is asked for a token by the
+		// constructor and a first API call.
+		wantCalls int
+		wantToken string
+	}{
+		{
+			name:      "seeded token used until expiry",
+			token:     "seeded",
+			expireAt:  time.Now().Add(time.Hour),
+			wantCalls: 0,
+			wantToken: "seeded",
+		},
+		{
+			name:      "seeded token without expiry",
+			token:     testToken,
+			wantCalls: 0,
+			wantToken: testToken,
+		},
+		{
+			name:      "seeded token about to expire",
+			token:     "seeded",
+			expireAt:  time.Now().Add(time.Second),
+			wantCalls: 1,
+			wantToken: "sourced",
+		},
+		{
+			name:      "no seeded token",
+			wantCalls: 1,
+			wantToken: "sourced",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			source := func(context.Context) (string, time.Time, error) {
+				calls++
+				return "sourced", time.Now().Add(time.Hour), nil
+			}
+
+			host, account, region := "https://api.example.com", testCloudaccount, "us-region-1"
+			client, err := NewClientWithTokenSource(context.Background(), &host, &account, &region, tc.token, tc.expireAt, source)
+			if err != nil {
+				t.Fatalf("NewClientWithTokenSource() error = %v", err)
+			}
+			token, err := client.accessToken(context.Background())
+			if err != nil {
+				t.Fatalf("accessToken() error = %v", err)
+			}
+			if token != tc.wantToken {
+				t.Errorf("accessToken() = %q, want %q", token, tc.wantToken)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("source called %d times, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
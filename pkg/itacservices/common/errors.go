@@ -0,0 +1,146 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestIDHeader is the response header carrying the id the API assigned to
+// a request, to be quoted when reporting problems.
+const requestIDHeader = "X-Request-Id"
+
+// APIError is an error response of the ITAC API. Responses with a status
+// that has a dedicated type are returned as NotFoundError, ConflictError,
+// ForbiddenError, RateLimitedError or ValidationError, all of which unwrap to
+// the APIError.
+type APIError struct {
+	// Code is the error code in the response body.
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Details []interface{} `json:"details"`
+	// StatusCode is the HTTP status of the response.
+	StatusCode int `json:"-"`
+	// RequestID is the id the API assigned to the request, if any.
+	RequestID string `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d)", strings.ToLower(http.StatusText(e.StatusCode)), e.StatusCode)
+	if e.Message != "" {
+		fmt.Fprintf(&b, ", message: %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request id: %s", e.RequestID)
+	}
+	return b.String()
+}
+
+// NotFoundError is returned for 404 responses.
+type NotFoundError struct{ *APIError }
+
+func (e *NotFoundError) Unwrap() error { return e.APIError }
+
+// ConflictError is returned for 409 responses.
+type ConflictError struct{ *APIError }
+
+func (e *ConflictError) Unwrap() error { return e.APIError }
+
+// ForbiddenError is returned for 403 responses.
+type ForbiddenError struct{ *APIError }
+
+func (e *ForbiddenError) Unwrap() error { return e.APIError }
+
+// RateLimitedError is returned for 429 responses once retries are exhausted.
+type RateLimitedError struct {
+	*APIError
+	// RetryAfter is the wait the API asked for, zero if it did not say.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Unwrap() error { return e.APIError }
+
+// ValidationError is returned for 400 and 422 responses.
+type ValidationError struct{ *APIError }
+
+func (e *ValidationError) Unwrap() error { return e.APIError }
+
+// ProvisioningError is returned when a resource being waited for ends up in
+// a failed phase or does not become ready in time. It carries the last phase
+// observed and the message the API gave with it, which usually tells why,
+// such as a quota or capacity shortage.
+type ProvisioningError struct {
+	// Kind is the kind of resource, such as instance or iks cluster.
+	Kind       string
+	ResourceId string
+	Phase      string
+	Message    string
+	// Err ends the wait before the resource failed, such as the context
+	// deadline or an error reading the resource. It is nil when the resource
+	// reached its failed phase.
+	Err error
+}
+
+func (e *ProvisioningError) Error() string {
+	var b strings.Builder
+	if e.Err == nil {
+		fmt.Fprintf(&b, "%s %s failed", e.Kind, e.ResourceId)
+	} else {
+		fmt.Fprintf(&b, "%s %s not ready", e.Kind, e.ResourceId)
+	}
+	if e.Phase != "" {
+		fmt.Fprintf(&b, ", phase: %s", e.Phase)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ", message: %s", e.Message)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	return b.String()
+}
+
+func (e *ProvisioningError) Unwrap() error { return e.Err }
+
+// IsNotFound reports whether err is or wraps a NotFoundError.
+func IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// newAPIError builds the typed error for a response with status code, headers
+// header and body retval. header may be nil.
+func newAPIError(code int, header http.Header, retval []byte) error {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(retval, apiErr); err != nil {
+		// not a JSON error document, keep the raw body as message
+		apiErr = &APIError{Message: strings.TrimSpace(string(retval))}
+	}
+	apiErr.StatusCode = code
+	if header != nil {
+		apiErr.RequestID = header.Get(requestIDHeader)
+	}
+
+	switch code {
+	case http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case http.StatusConflict:
+		return &ConflictError{apiErr}
+	case http.StatusForbidden:
+		return &ForbiddenError{apiErr}
+	case http.StatusTooManyRequests:
+		rateLimited := &RateLimitedError{APIError: apiErr}
+		if header != nil {
+			rateLimited.RetryAfter, _ = parseRetryAfter(header.Get("Retry-After"))
+		}
+		return rateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return &ValidationError{apiErr}
+	default:
+		return apiErr
+	}
+}
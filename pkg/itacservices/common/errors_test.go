@@ -0,0 +1,202 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+		body   string
+
+		// as reports whether the error is of the expected type.
+		as          func(error) bool
+		wantAPIErr  APIError
+		wantMessage string
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"code": 5, "message": "instance not found"}`,
+			as:     isType[*NotFoundError],
+			wantAPIErr: APIError{
+				Code: 5, Message: "instance not found", StatusCode: http.StatusNotFound,
+			},
+			wantMessage: "not found (404), message: instance not found",
+		},
+		{
+			name:   "conflict",
+			status: http.StatusConflict,
+			body:   `{"code": 6, "message": "name already in use"}`,
+			as:     isType[*ConflictError],
+			wantAPIErr: APIError{
+				Code: 6, Message: "name already in use", StatusCode: http.StatusConflict,
+			},
+			wantMessage: "conflict (409), message: name already in use",
+		},
+		{
+			name:   "forbidden",
+			status: http.StatusForbidden,
+			body:   `{"code": 7, "message": "denied"}`,
+			as:     isType[*ForbiddenError],
+			wantAPIErr: APIError{
+				Code: 7, Message: "denied", StatusCode: http.StatusForbidden,
+			},
+			wantMessage: "forbidden (403), message: denied",
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"code": 3, "message": "invalid name"}`,
+			as:     isType[*ValidationError],
+			wantAPIErr: APIError{
+				Code: 3, Message: "invalid name", StatusCode: http.StatusBadRequest,
+			},
+			wantMessage: "bad request (400), message: invalid name",
+		},
+		{
+			name:   "unprocessable entity",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code": 3, "message": "invalid size"}`,
+			as:     isType[*ValidationError],
+			wantAPIErr: APIError{
+				Code: 3, Message: "invalid size", StatusCode: http.StatusUnprocessableEntity,
+			},
+			wantMessage: "unprocessable entity (422), message: invalid size",
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			header: http.Header{"Retry-After": {"30"}},
+			body:   `{"code": 8, "message": "slow down"}`,
+			as: func(err error) bool {
+				var rateLimited *RateLimitedError
+				return errors.As(err, &rateLimited) && rateLimited.RetryAfter == 30*time.Second
+			},
+			wantAPIErr: APIError{
+				Code: 8, Message: "slow down", StatusCode: http.StatusTooManyRequests,
+			},
+			wantMessage: "too many requests (429), message: slow down",
+		},
+		{
+			name:   "other status",
+			status: http.StatusInternalServerError,
+			body:   `{"code": 13, "message": "internal"}`,
+			as:     isType[*APIError],
+			wantAPIErr: APIError{
+				Code: 13, Message: "internal", StatusCode: http.StatusInternalServerError,
+			},
+			wantMessage: "internal server error (500), message: internal",
+		},
+		{
+			name:   "grpc details and request id",
+			status: http.StatusBadRequest,
+			header: http.Header{"X-Request-Id": {"req-123"}},
+			body: `{"code": 3, "message": "quota exceeded", "details": [
+				{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "QUOTA_EXCEEDED", "metadata": {"limit": "10"}}
+			]}`,
+			as: isType[*ValidationError],
+			wantAPIErr: APIError{
+				Code:    3,
+				Message: "quota exceeded",
+				Details: []interface{}{map[string]interface{}{
+					"@type":    "type.googleapis.com/google.rpc.ErrorInfo",
+					"reason":   "QUOTA_EXCEEDED",
+					"metadata": map[string]interface{}{"limit": "10"},
+				}},
+				StatusCode: http.StatusBadRequest,
+				RequestID:  "req-123",
+			},
+			wantMessage: "bad request (400), message: quota exceeded, request id: req-123",
+		},
+		{
+			name:   "body not json",
+			status: http.StatusBadGateway,
+			body:   "upstream unavailable\n",
+			as:     isType[*APIError],
+			wantAPIErr: APIError{
+				Message: "upstream unavailable", StatusCode: http.StatusBadGateway,
+			},
+			wantMessage: "bad gateway (502), message: upstream unavailable",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := newAPIError(tc.status, tc.header, []byte(tc.body))
+			if !tc.as(err) {
+				t.Errorf("newAPIError() = %T %v, not of the expected type", err, err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("newAPIError() = %T, does not unwrap to an *APIError", err)
+			}
+			if !reflect.DeepEqual(*apiErr, tc.wantAPIErr) {
+				t.Errorf("APIError = %+v, want %+v", *apiErr, tc.wantAPIErr)
+			}
+			if err.Error() != tc.wantMessage {
+				t.Errorf("Error() = %q, want %q", err.Error(), tc.wantMessage)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := newAPIError(http.StatusNotFound, nil, []byte(`{}`))
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "not found", err: notFound, want: true},
+		{name: "wrapped not found", err: fmt.Errorf("error reading instance: %w", notFound), want: true},
+		{name: "other api error", err: newAPIError(http.StatusConflict, nil, []byte(`{}`))},
+		{name: "other error", err: errors.New("not found")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsNotFound(tc.err); got != tc.want {
+				t.Errorf("IsNotFound(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMakeAPICallTypedError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-456")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code": 5, "message": "gone"}`))
+	}))
+	defer srv.Close()
+
+	status, _, err := MakeGetAPICall(context.Background(), srv.URL, "token", nil)
+	if status != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", status, http.StatusNotFound)
+	}
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("MakeGetAPICall() error = %T %v, want a *NotFoundError", err, err)
+	}
+	if notFound.RequestID != "req-456" || notFound.Message != "gone" {
+		t.Errorf("NotFoundError = %+v, want request id req-456 and message gone", notFound.APIError)
+	}
+}
+
+// isType reports whether err is an error of type T.
+func isType[T error](err error) bool {
+	_, ok := err.(T)
+	return ok
+}
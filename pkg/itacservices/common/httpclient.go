@@ -10,158 +10,139 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // MakeGetAPICall :
 func MakeGetAPICall(ctx context.Context, connURL, auth string, payload []byte) (int, []byte, error) {
-	// logger := log.FromContext(ctx).WithName("common.MakeGetAPICall")
-	// http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: false}
-	req, err := http.NewRequest("GET", connURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return http.StatusInternalServerError, nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if auth != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth))
-	}
-	printRequest(req)
-	retries := 3
-	body := []byte{}
-	retcode := http.StatusOK
-	for try := 1; try <= retries; try++ {
-		client := &http.Client{Timeout: 60 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			if try == retries {
-				return http.StatusInternalServerError, nil,
-					errors.New("error conencting to  api service")
-			}
-			time.Sleep(5 * time.Second)
-			continue
-		}
-		defer resp.Body.Close()
-		body, _ = io.ReadAll(resp.Body)
-		retcode = resp.StatusCode
-		break
-	}
-	// body = []byte(sampleFilesystemList)
-	return retcode, body, nil
+	return makeAPICall(ctx, http.MethodGet, connURL, auth, payload)
 }
 
 // MakePOSTAPICall :
 func MakePOSTAPICall(ctx context.Context, connURL, auth string, payload []byte) (int, []byte, error) {
+	return makeAPICall(ctx, http.MethodPost, connURL, auth, payload)
+}
 
-	// http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: false}
-	req, err := http.NewRequest("POST", connURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return http.StatusInternalServerError, nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	if auth != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth))
+// MakeDeleteAPICall :
+func MakeDeleteAPICall(ctx context.Context, connURL string, auth string, payload []byte) (int, []byte, error) {
+	return makeAPICall(ctx, http.MethodDelete, connURL, auth, payload)
+}
+
+// MakePutAPICall :
+func MakePutAPICall(ctx context.Context, connURL, auth string, payload []byte) (int, []byte, error) {
+	return makeAPICall(ctx, http.MethodPut, connURL, auth, payload)
+}
+
+// makeAPICall sends the request bound to ctx, retrying it according to the
+// retry policy of the context. Responses outside the 2xx range are returned
+// along with their typed error. A cancelled context aborts both the in-flight
+// request and the wait between attempts.
+func makeAPICall(ctx context.Context, method, connURL, auth string, payload []byte) (int, []byte, error) {
+	ctx = MaskSecrets(ctx)
+	policy := retryPolicyFromContext(ctx)
+	check := resendCheckFromContext(ctx)
+	if isIdempotent(method) {
+		check = nil
+	} else if check != nil {
+		policy.RetryNonIdempotent = true
 	}
-	printRequest(req)
-	retries := 3
-	body := []byte{}
-	retcode := http.StatusOK
-	for try := 1; try <= retries; try++ {
-		client := &http.Client{Timeout: 60 * time.Second}
+	backoff := policy.backoff()
+	client := HTTPClientFromContext(ctx)
+	for try := 0; ; try++ {
+		// the request is rebuilt for every attempt as sending drains its body
+		req, err := http.NewRequestWithContext(ctx, method, connURL, bytes.NewBuffer(payload))
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if method == http.MethodPost {
+			req.Header.Set("Accept", "application/json")
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth))
+		}
+		tflog.Debug(ctx, "api request", map[string]any{"method": method, "url": connURL, "attempt": try + 1})
+		tflog.Trace(ctx, "api request details", map[string]any{"headers": fmt.Sprint(req.Header), "body": string(payload)})
+
 		resp, err := client.Do(req)
 		if err != nil {
-			if try == retries {
+			if ctx.Err() != nil {
+				return http.StatusInternalServerError, nil, ctx.Err()
+			}
+			tflog.Debug(ctx, "api request failed", map[string]any{"method": method, "url": connURL, "error": err.Error()})
+			if try >= policy.MaxRetries || !policy.retryable(method, nil) {
 				return http.StatusInternalServerError, nil,
 					errors.New("error conencting to  api service")
 			}
-			time.Sleep(5 * time.Second)
+			if err := sleepContext(ctx, policy.wait(backoff, nil)); err != nil {
+				return http.StatusInternalServerError, nil, err
+			}
+			if check != nil {
+				body, err := checkResend(ctx, check, connURL)
+				if err != nil {
+					return http.StatusInternalServerError, nil, err
+				}
+				if body != nil {
+					return http.StatusOK, body, nil
+				}
+			}
 			continue
 		}
-		defer resp.Body.Close()
-		body, _ = io.ReadAll(resp.Body)
-		retcode = resp.StatusCode
-		break
-	}
-	return retcode, body, nil
-}
-
-// MakeDeleteAPICall :
-func MakeDeleteAPICall(ctx context.Context, connURL string, auth string, payload []byte) (int, []byte, error) {
-	req, err := http.NewRequest("DELETE", connURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return http.StatusInternalServerError, nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if auth != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth))
-	}
-	printRequest(req)
-	retries := 3
-	body := []byte{}
-	retcode := http.StatusOK
-	for try := 1; try <= retries; try++ {
-		client := &http.Client{Timeout: 60 * time.Second}
-		resp, err := client.Do(req)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
 		if err != nil {
-			if try == retries {
-				return http.StatusInternalServerError, nil,
-					errors.New("error conencting to  api service")
+			if ctx.Err() != nil {
+				return http.StatusInternalServerError, nil, ctx.Err()
+			}
+			return http.StatusInternalServerError, nil, fmt.Errorf("error reading api response: %v", err)
+		}
+		tflog.Debug(ctx, "api response", map[string]any{"method": method, "url": connURL, "status": resp.StatusCode})
+		tflog.Trace(ctx, "api response details", map[string]any{"body": string(body)})
+		if try < policy.MaxRetries && policy.retryable(method, resp) {
+			if err := sleepContext(ctx, policy.wait(backoff, resp)); err != nil {
+				return http.StatusInternalServerError, nil, err
+			}
+			// a request turned away with 429 was not processed
+			if check != nil && resp.StatusCode != http.StatusTooManyRequests {
+				body, err := checkResend(ctx, check, connURL)
+				if err != nil {
+					return http.StatusInternalServerError, nil, err
+				}
+				if body != nil {
+					return http.StatusOK, body, nil
+				}
 			}
-			time.Sleep(5 * time.Second)
 			continue
 		}
-		defer resp.Body.Close()
-		body, _ = io.ReadAll(resp.Body)
-		retcode = resp.StatusCode
-		break
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp.StatusCode, body, newAPIError(resp.StatusCode, resp.Header, body)
+		}
+		return resp.StatusCode, body, nil
 	}
-	return retcode, body, nil
 }
 
-// MakePutAPICall :
-func MakePutAPICall(ctx context.Context, connURL, auth string, payload []byte) (int, []byte, error) {
-	// logger := log.FromContext(ctx).WithName("common.MakeGetAPICall")
-	// http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: false}
-	req, err := http.NewRequest("PUT", connURL, bytes.NewBuffer(payload))
+// checkResend runs check before a request to connURL is sent again. It
+// returns the body of the resource an earlier attempt created, if any.
+func checkResend(ctx context.Context, check ResendCheck, connURL string) ([]byte, error) {
+	body, err := check(ctx)
 	if err != nil {
-		return http.StatusInternalServerError, nil, err
+		return nil, fmt.Errorf("error checking whether the request took effect: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	if auth != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth))
-	}
-	printRequest(req)
-	retries := 3
-	body := []byte{}
-	retcode := http.StatusOK
-	for try := 1; try <= retries; try++ {
-		client := &http.Client{Timeout: 60 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			if try == retries {
-				return http.StatusInternalServerError, nil,
-					errors.New("error conencting to  api service")
-			}
-			time.Sleep(5 * time.Second)
-			continue
-		}
-		defer resp.Body.Close()
-		body, _ = io.ReadAll(resp.Body)
-		retcode = resp.StatusCode
-		break
+	if body != nil {
+		tflog.Info(ctx, "api request took effect without an answer, not sending it again", map[string]any{"url": connURL})
 	}
-	// body = []byte(sampleFilesystemList)
-	return retcode, body, nil
+	return body, nil
 }
 
-func printRequest(req *http.Request) {
-	fmt.Printf("Method: %s\nURL: %s\nHeaders: %v\n", req.Method, req.URL.String(), req.Header)
-	//fmt.Printf("nHeaders: %v\n", req.Header)
-
-	if req.Body != nil {
-		bodyBytes, _ := io.ReadAll(req.Body)
-		fmt.Printf("Body: %s\n", string(bodyBytes))
-
-		// Recreate the body since io.ReadAll drains it
-		req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+// sleepContext waits for d or until ctx is cancelled, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// sensitiveLogFields are log field keys whose values are always masked.
+var sensitiveLogFields = []string{
+	"authorization", "Authorization",
+	"token", "access_token", "apitoken",
+	"password",
+	"clientsecret", "client_secret",
+	"accessKey", "secretKey",
+	"kubeconfig",
+	"userData", "user_data",
+}
+
+// sensitiveLogPatterns mask credentials embedded in logged headers and
+// request or response bodies: bearer and basic auth values as well as JSON
+// members holding passwords, keys, tokens, kubeconfigs and user data.
+var sensitiveLogPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9\-._~+/]+=*`),
+	regexp.MustCompile(`"(password|secretKey|accessKey|access_token|token|kubeconfig|userData|client_secret)"\s*:\s*"(?:[^"\\]|\\.)*"`),
+}
+
+// MaskSecrets returns a context whose tflog output has credentials and other
+// secrets masked.
+func MaskSecrets(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveLogFields...)
+	ctx = tflog.MaskAllFieldValuesRegexes(ctx, sensitiveLogPatterns...)
+	return tflog.MaskMessageRegexes(ctx, sensitiveLogPatterns...)
+}
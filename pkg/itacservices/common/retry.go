@@ -0,0 +1,136 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// RetryPolicy controls how the API helpers retry failed calls. Transport
+// errors and 429, 502, 503 and 504 responses are retried with exponential
+// backoff and jitter; a Retry-After header from the server takes precedence
+// over the computed wait.
+type RetryPolicy struct {
+	// MaxRetries is the number of attempts made after the first one.
+	MaxRetries int
+	// MinWait is the wait before the first retry, doubled on every attempt.
+	MinWait time.Duration
+	// MaxWait caps the wait between two attempts, including Retry-After.
+	MaxWait time.Duration
+	// RetryNonIdempotent allows retrying POST requests that may have
+	// reached the server.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used when no policy is attached to the context.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinWait:    1 * time.Second,
+	MaxWait:    30 * time.Second,
+}
+
+type retryPolicyKey struct{}
+
+// ResendCheck tells whether a POST that may have reached the API without an
+// answer took effect. It returns the body of the resource the POST created,
+// or nil if the POST may be sent again.
+type ResendCheck func(ctx context.Context) ([]byte, error)
+
+type resendCheckKey struct{}
+
+// WithResendCheck returns a context in which POST calls that fail in
+// transport or with a 502, 503 or 504 response are sent again, whatever the
+// retry policy, after check found that the failed attempt did not take
+// effect. When it did, the call returns the body check found as the
+// response.
+func WithResendCheck(ctx context.Context, check ResendCheck) context.Context {
+	return context.WithValue(ctx, resendCheckKey{}, check)
+}
+
+func resendCheckFromContext(ctx context.Context) ResendCheck {
+	check, _ := ctx.Value(resendCheckKey{}).(ResendCheck)
+	return check
+}
+
+// WithRetryPolicy returns a context carrying the retry policy used by the
+// Make*APICall helpers.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryPolicyFromContext(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+func (p RetryPolicy) backoff() retry.Backoff {
+	b := retry.NewExponential(p.MinWait)
+	b = retry.WithJitterPercent(20, b)
+	return retry.WithCappedDuration(p.MaxWait, b)
+}
+
+// retryable reports whether a call may be sent again. A nil resp means the
+// call failed in transport.
+func (p RetryPolicy) retryable(method string, resp *http.Response) bool {
+	if resp == nil {
+		return isIdempotent(method) || p.RetryNonIdempotent
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// the server turned the request away without processing it
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method) || p.RetryNonIdempotent
+	}
+	return false
+}
+
+// wait returns how long to wait before the next attempt, preferring the
+// server's Retry-After over the backoff, never longer than MaxWait.
+func (p RetryPolicy) wait(b retry.Backoff, resp *http.Response) time.Duration {
+	d, _ := b.Next()
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			d = after
+		}
+	}
+	if p.MaxWait > 0 && d > p.MaxWait {
+		d = p.MaxWait
+	}
+	return d
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
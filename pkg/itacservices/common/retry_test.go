@@ -0,0 +1,334 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries retries twice without waiting noticeably.
+var fastRetries = RetryPolicy{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: time.Millisecond}
+
+// countingServer answers the requests with the statuses in turn, repeating
+// the last one, and counts them.
+func countingServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&count, 1))
+		status := statuses[len(statuses)-1]
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &count
+}
+
+func TestMakeAPICallRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{name: "429 retried until max retries", statuses: []int{http.StatusTooManyRequests}, wantStatus: http.StatusTooManyRequests, wantRequests: 3},
+		{name: "502 retried until max retries", statuses: []int{http.StatusBadGateway}, wantStatus: http.StatusBadGateway, wantRequests: 3},
+		{name: "503 retried until max retries", statuses: []int{http.StatusServiceUnavailable}, wantStatus: http.StatusServiceUnavailable, wantRequests: 3},
+		{name: "504 retried until max retries", statuses: []int{http.StatusGatewayTimeout}, wantStatus: http.StatusGatewayTimeout, wantRequests: 3},
+		{name: "success after retries", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, wantStatus: http.StatusOK, wantRequests: 3},
+		{name: "500 not retried", statuses: []int{http.StatusInternalServerError}, wantStatus: http.StatusInternalServerError, wantRequests: 1},
+		{name: "404 not retried", statuses: []int{http.StatusNotFound}, wantStatus: http.StatusNotFound, wantRequests: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, count := countingServer(t, nil, tc.statuses...)
+			ctx := WithRetryPolicy(context.Background(), fastRetries)
+
+			status, _, err := MakeGetAPICall(ctx, srv.URL, "token", nil)
+			if status != tc.wantStatus {
+				t.Errorf("status = %d, want %d", status, tc.wantStatus)
+			}
+			if (err == nil) != (tc.wantStatus == http.StatusOK) {
+				t.Errorf("error = %v for status %d", err, status)
+			}
+			if got := atomic.LoadInt32(count); got != tc.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tc.wantRequests)
+			}
+		})
+	}
+}
+
+func TestMakeAPICallPOSTResend(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		policy RetryPolicy
+		// checkBody is what the resend check finds, the check is not set
+		// when nil and finds nothing when empty.
+		checkBody []byte
+
+		wantStatus   int
+		wantBody     string
+		wantRequests int32
+		wantChecks   int32
+	}{
+		{
+			name:         "not resent without a check",
+			status:       http.StatusServiceUnavailable,
+			policy:       fastRetries,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			name:         "resent when allowed by the policy",
+			status:       http.StatusServiceUnavailable,
+			policy:       RetryPolicy{MaxRetries: 2, MinWait: time.Millisecond, MaxWait: time.Millisecond, RetryNonIdempotent: true},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "429 resent without a check",
+			status:       http.StatusTooManyRequests,
+			policy:       fastRetries,
+			wantStatus:   http.StatusTooManyRequests,
+			wantRequests: 3,
+		},
+		{
+			name:         "resent when the check finds nothing",
+			status:       http.StatusBadGateway,
+			policy:       fastRetries,
+			checkBody:    []byte{},
+			wantStatus:   http.StatusBadGateway,
+			wantRequests: 3,
+			wantChecks:   2,
+		},
+		{
+			name:         "not resent when the check finds the resource",
+			status:       http.StatusGatewayTimeout,
+			policy:       fastRetries,
+			checkBody:    []byte(`{"id": "created"}`),
+			wantStatus:   http.StatusOK,
+			wantBody:     `{"id": "created"}`,
+			wantRequests: 1,
+			wantChecks:   1,
+		},
+		{
+			name:         "429 resent without running the check",
+			status:       http.StatusTooManyRequests,
+			policy:       fastRetries,
+			checkBody:    []byte(`{"id": "created"}`),
+			wantStatus:   http.StatusTooManyRequests,
+			wantRequests: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, count := countingServer(t, nil, tc.status)
+			ctx := WithRetryPolicy(context.Background(), tc.policy)
+			var checks int32
+			if tc.checkBody != nil {
+				ctx = WithResendCheck(ctx, func(context.Context) ([]byte, error) {
+					atomic.AddInt32(&checks, 1)
+					if len(tc.checkBody) == 0 {
+						return nil, nil
+					}
+					return tc.checkBody, nil
+				})
+			}
+
+			status, body, _ := MakePOSTAPICall(ctx, srv.URL, "token", []byte(`{}`))
+			if status != tc.wantStatus {
+				t.Errorf("status = %d, want %d", status, tc.wantStatus)
+			}
+			if tc.wantBody != "" && string(body) != tc.wantBody {
+				t.Errorf("body = %s, want %s", body, tc.wantBody)
+			}
+			if got := atomic.LoadInt32(count); got != tc.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tc.wantRequests)
+			}
+			if got := atomic.LoadInt32(&checks); got != tc.wantChecks {
+				t.Errorf("check ran %d times, want %d", got, tc.wantChecks)
+			}
+		})
+	}
+}
+
+func TestMakeAPICallTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	var checks int32
+	ctx := WithRetryPolicy(context.Background(), fastRetries)
+	ctx = WithResendCheck(ctx, func(context.Context) ([]byte, error) {
+		atomic.AddInt32(&checks, 1)
+		return nil, nil
+	})
+	if _, _, err := MakePOSTAPICall(ctx, url, "token", nil); err == nil {
+		t.Fatal("MakePOSTAPICall() succeeded against a closed server")
+	}
+	if got := atomic.LoadInt32(&checks); got != 2 {
+		t.Errorf("check ran %d times, want 2", got)
+	}
+}
+
+func TestMakeAPICallRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxWait    time.Duration
+		// wantMin and wantMax bound how long the call takes.
+		wantMin, wantMax time.Duration
+	}{
+		{
+			name:       "seconds",
+			retryAfter: "1",
+			maxWait:    time.Minute,
+			wantMin:    time.Second,
+			wantMax:    5 * time.Second,
+		},
+		{
+			name:       "http date",
+			retryAfter: time.Now().Add(3 * time.Second).UTC().Format(http.TimeFormat),
+			maxWait:    time.Minute,
+			// the date has a one second resolution
+			wantMin: time.Second,
+			wantMax: 5 * time.Second,
+		},
+		{
+			name:       "capped by max wait",
+			retryAfter: "3600",
+			maxWait:    10 * time.Millisecond,
+			wantMin:    10 * time.Millisecond,
+			wantMax:    time.Second,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, count := countingServer(t, http.Header{"Retry-After": {tc.retryAfter}}, http.StatusServiceUnavailable, http.StatusOK)
+			ctx := WithRetryPolicy(context.Background(), RetryPolicy{MaxRetries: 1, MinWait: time.Millisecond, MaxWait: tc.maxWait})
+
+			start := time.Now()
+			status, _, err := MakeGetAPICall(ctx, srv.URL, "token", nil)
+			elapsed := time.Since(start)
+			if err != nil || status != http.StatusOK {
+				t.Fatalf("MakeGetAPICall() = %d, %v", status, err)
+			}
+			if got := atomic.LoadInt32(count); got != 2 {
+				t.Errorf("server got %d requests, want 2", got)
+			}
+			if elapsed < tc.wantMin || elapsed > tc.wantMax {
+				t.Errorf("call took %s, want between %s and %s", elapsed, tc.wantMin, tc.wantMax)
+			}
+		})
+	}
+}
+
+func TestMakeAPICallCancelledWhileWaiting(t *testing.T) {
+	srv, count := countingServer(t, http.Header{"Retry-After": {"3600"}}, http.StatusServiceUnavailable)
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{MaxRetries: 3, MinWait: time.Hour, MaxWait: time.Hour})
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := MakeGetAPICall(ctx, srv.URL, "token", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("MakeGetAPICall() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("call took %s after its context expired", elapsed)
+	}
+	if got := atomic.LoadInt32(count); got != 1 {
+		t.Errorf("server got %d requests, want 1", got)
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleepContext() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := sleepContext(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("sleepContext() returned after %s, not on cancellation", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+		// approx allows the result to be up to one second shorter, for
+		// dates.
+		approx bool
+	}{
+		{name: "empty", value: ""},
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOk: true},
+		{name: "zero", value: "0", want: 0, wantOk: true},
+		{name: "negative", value: "-1"},
+		{name: "garbage", value: "soon"},
+		{name: "future date", value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), want: time.Minute, wantOk: true, approx: true},
+		{name: "past date", value: "Mon, 02 Jan 2006 15:04:05 GMT", want: 0, wantOk: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tc.value)
+			if ok != tc.wantOk {
+				t.Fatalf("parseRetryAfter(%q) ok = %v, want %v", tc.value, ok, tc.wantOk)
+			}
+			if tc.approx {
+				if got > tc.want || got < tc.want-time.Second {
+					t.Errorf("parseRetryAfter(%q) = %s, want about %s", tc.value, got, tc.want)
+				}
+			} else if got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	for method, want := range map[string]bool{
+		http.MethodGet:    true,
+		http.MethodHead:   true,
+		http.MethodPut:    true,
+		http.MethodDelete: true,
+		http.MethodPost:   false,
+		http.MethodPatch:  false,
+	} {
+		if got := isIdempotent(method); got != want {
+			t.Errorf("isIdempotent(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyWaitCapped(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, MinWait: time.Second, MaxWait: 3 * time.Second}
+	b := policy.backoff()
+	for i := 0; i < 6; i++ {
+		if d := policy.wait(b, nil); d > policy.MaxWait {
+			t.Errorf("wait %d = %s, want at most %s", i, d, policy.MaxWait)
+		}
+	}
+}
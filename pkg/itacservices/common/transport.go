@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultRequestTimeout bounds a single attempt of an API call.
+const defaultRequestTimeout = 60 * time.Second
+
+// TransportConfig tunes the HTTP client shared by all API calls. Certificate
+// values are either a path to a PEM file or the PEM data itself.
+type TransportConfig struct {
+	// ProxyURL overrides the proxy taken from the HTTPS_PROXY, HTTP_PROXY
+	// and NO_PROXY environment variables.
+	ProxyURL string
+	// CABundle holds certificates trusted in addition to the system roots.
+	CABundle          string
+	ClientCertificate string
+	ClientKey         string
+	// RequestTimeout bounds a single attempt of an API call.
+	RequestTimeout time.Duration
+}
+
+var defaultHTTPClient = &http.Client{Timeout: defaultRequestTimeout}
+
+type httpClientKey struct{}
+
+// WithHTTPClient returns a context carrying the HTTP client used by the
+// Make*APICall helpers.
+func WithHTTPClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+// HTTPClientFromContext returns the HTTP client attached to ctx, or a shared
+// default client.
+func HTTPClientFromContext(ctx context.Context) *http.Client {
+	if client, ok := ctx.Value(httpClientKey{}).(*http.Client); ok && client != nil {
+		return client
+	}
+	return defaultHTTPClient
+}
+
+// NewHTTPClient builds an HTTP client with a pooled transport configured by
+// cfg. The client is safe for concurrent use and should be reused.
+func NewHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url %q", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CABundle != "" {
+		caPEM, err := readPEM(cfg.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ca bundle: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("ca bundle contains no PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertificate != "" || cfg.ClientKey != "" {
+		if cfg.ClientCertificate == "" || cfg.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and client key must be set together")
+		}
+		certPEM, err := readPEM(cfg.ClientCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client certificate: %v", err)
+		}
+		keyPEM, err := readPEM(cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client key: %v", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	timeout := cfg.RequestTimeout
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// readPEM returns value itself if it holds PEM data, otherwise the contents
+// of the file it names.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
@@ -0,0 +1,227 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes data to a file of the test's temporary directory and
+// returns its path.
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// certPEM encodes the DER certificate as PEM.
+func certPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// newClientCertificate returns a self-signed client certificate and its key
+// as PEM.
+func newClientCertificate(t *testing.T) (cert, key []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "terraform"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM(der), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// get reports the error of a GET of url with client.
+func get(client *http.Client, url string) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func TestNewHTTPClientCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	caPEM := certPEM(srv.Certificate().Raw)
+
+	tests := []struct {
+		name     string
+		caBundle string
+		// wantErr is part of the error of NewHTTPClient, if any.
+		wantErr string
+		// wantTrusted tells whether the server certificate is trusted.
+		wantTrusted bool
+	}{
+		{name: "system roots only", wantTrusted: false},
+		{name: "inline pem", caBundle: string(caPEM), wantTrusted: true},
+		{name: "file path", caBundle: writeFile(t, "ca.pem", caPEM), wantTrusted: true},
+		{name: "missing file", caBundle: filepath.Join(t.TempDir(), "missing.pem"), wantErr: "error reading ca bundle"},
+		{name: "no certificates", caBundle: writeFile(t, "empty.pem", []byte("not a certificate")), wantErr: "ca bundle contains no PEM encoded certificates"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(TransportConfig{CABundle: tc.caBundle})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("NewHTTPClient() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewHTTPClient() error = %v", err)
+			}
+			err = get(client, srv.URL)
+			if tc.wantTrusted && err != nil {
+				t.Errorf("GET error = %v, want the server to be trusted", err)
+			}
+			if !tc.wantTrusted && err == nil {
+				t.Errorf("GET succeeded, want the server certificate to be rejected")
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientClientCertificate(t *testing.T) {
+	cert, key := newClientCertificate(t)
+	otherCert, _ := newClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(cert)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	caBundle := string(certPEM(srv.Certificate().Raw))
+
+	tests := []struct {
+		name     string
+		cert     string
+		key      string
+		wantErr  string
+		wantAuth bool
+	}{
+		{name: "no client certificate", wantAuth: false},
+		{name: "inline pem", cert: string(cert), key: string(key), wantAuth: true},
+		{name: "file paths", cert: writeFile(t, "client.pem", cert), key: writeFile(t, "client.key", key), wantAuth: true},
+		{name: "certificate without key", cert: string(cert), wantErr: "client certificate and client key must be set together"},
+		{name: "key without certificate", key: string(key), wantErr: "client certificate and client key must be set together"},
+		{name: "missing certificate file", cert: filepath.Join(t.TempDir(), "missing.pem"), key: string(key), wantErr: "error reading client certificate"},
+		{name: "missing key file", cert: string(cert), key: filepath.Join(t.TempDir(), "missing.key"), wantErr: "error reading client key"},
+		{name: "mismatched pair", cert: string(otherCert), key: string(key), wantErr: "error loading client certificate"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(TransportConfig{CABundle: caBundle, ClientCertificate: tc.cert, ClientKey: tc.key})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("NewHTTPClient() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewHTTPClient() error = %v", err)
+			}
+			err = get(client, srv.URL)
+			if tc.wantAuth && err != nil {
+				t.Errorf("GET error = %v, want the client certificate to be accepted", err)
+			}
+			if !tc.wantAuth && err == nil {
+				t.Errorf("GET succeeded, want the server to require a client certificate")
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(TransportConfig{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	if err := get(client, "http://api.example.invalid/v1/instances"); err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	if proxied != "http://api.example.invalid/v1/instances" {
+		t.Errorf("proxy got %q, want the request for http://api.example.invalid/v1/instances", proxied)
+	}
+
+	for _, invalid := range []string{"proxy.example.com:3128", "://proxy", "http://", "/proxy"} {
+		if _, err := NewHTTPClient(TransportConfig{ProxyURL: invalid}); err == nil || !strings.Contains(err.Error(), "invalid proxy url") {
+			t.Errorf("NewHTTPClient(ProxyURL: %q) error = %v, want an invalid proxy url error", invalid, err)
+		}
+	}
+}
+
+func TestNewHTTPClientRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client, err := NewHTTPClient(TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	if client.Timeout != defaultRequestTimeout {
+		t.Errorf("default Timeout = %s, want %s", client.Timeout, defaultRequestTimeout)
+	}
+
+	client, err = NewHTTPClient(TransportConfig{RequestTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	start := time.Now()
+	err = get(client, srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "Client.Timeout exceeded") {
+		t.Errorf("GET error = %v, want a client timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GET returned after %s, want about 50ms", elapsed)
+	}
+}
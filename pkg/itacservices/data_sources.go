@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -53,15 +52,12 @@ func (client *IDCServicesClient) GetMachineImages(ctx context.Context) (*Machine
 	if err != nil {
 		return nil, fmt.Errorf("error parsing the url")
 	}
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		tflog.Debug(ctx, "machine images api error", map[string]any{"retcode": retcode, "err": err, "token": *client.Apitoken})
-		return nil, fmt.Errorf("error reading machine images")
-	}
-	tflog.Debug(ctx, "machine images api", map[string]any{"retcode": retcode, "retval": string(retval), "token": *client.Apitoken})
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		tflog.Debug(ctx, "machine images api error", map[string]any{"retcode": retcode, "err": err})
+		return nil, fmt.Errorf("error reading machine images: %w", err)
 	}
+	tflog.Debug(ctx, "machine images api", map[string]any{"retcode": retcode})
 
 	images := MachineImageResponse{}
 	if err := json.Unmarshal(retval, &images); err != nil {
@@ -83,14 +79,11 @@ func (client *IDCServicesClient) GetInstanceTypes(ctx context.Context) (*Instanc
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	_, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading machine images")
+		return nil, fmt.Errorf("error reading machine images: %w", err)
 	}
 
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
-	}
 	instType := InstanceTypeResponse{}
 	if err := json.Unmarshal(retval, &instType); err != nil {
 		return nil, fmt.Errorf("error parsing machine image response")
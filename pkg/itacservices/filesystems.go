@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"time"
 
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 
@@ -109,15 +107,12 @@ func (client *IDCServicesClient) GetFilesystems(ctx context.Context) (*Filesyste
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
-	tflog.Debug(ctx, "filesystem read api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	tflog.Debug(ctx, "filesystem read api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading filesystems")
+		return nil, fmt.Errorf("error reading filesystems: %w", err)
 	}
 
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
-	}
 	filesystems := Filesystems{}
 	if err := json.Unmarshal(retval, &filesystems); err != nil {
 		return nil, fmt.Errorf("error parsing filesystem response")
@@ -157,12 +152,9 @@ func (client *IDCServicesClient) GenerateFilesystemLoginCredentials(ctx context.
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	_, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error generating login credentials")
-	}
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error generating login credentials: %w", err)
 	}
 	creds := LoginCreds{}
 	if err := json.Unmarshal(retval, &creds); err != nil {
@@ -191,14 +183,11 @@ func (client *IDCServicesClient) CreateFilesystem(ctx context.Context, in *Files
 		return nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "filesystem create api", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
-	tflog.Debug(ctx, "filesystem create api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	tflog.Debug(ctx, "filesystem create api", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
+	tflog.Debug(ctx, "filesystem create api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading filesystem create response")
-	}
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading filesystem create response: %w", err)
 	}
 
 	filesystem := &Filesystem{}
@@ -206,8 +195,7 @@ func (client *IDCServicesClient) CreateFilesystem(ctx context.Context, in *Files
 		return nil, fmt.Errorf("error parsing filesystem response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(300*time.Second, backoffTimer)
+	backoffTimer := waitBackoff(ctx, DefaultFilesystemTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
 		filesystem, err = client.GetFilesystemByResourceId(ctx, filesystem.Metadata.ResourceId)
@@ -233,8 +221,6 @@ func (client *IDCServicesClient) CreateFilesystem(ctx context.Context, in *Files
 	// }
 	// filesystem.Status.Mount.Password = *password
 
-	tflog.Debug(ctx, "filesystem generate passwordi", map[string]any{"password": filesystem.Status.Mount.Password})
-
 	return filesystem, nil
 }
 
@@ -255,13 +241,9 @@ func (client *IDCServicesClient) GetFilesystemByResourceId(ctx context.Context,
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading filesystem by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading filesystem by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "filesystem read api", map[string]any{"retcode": retcode})
@@ -289,18 +271,20 @@ func (client *IDCServicesClient) DeleteFilesystemByResourceId(ctx context.Contex
 		return fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting filesystem by resource id")
+		return fmt.Errorf("error deleting filesystem by resource id: %w", err)
 	}
 
-	tflog.Debug(ctx, "filesystem delete api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	tflog.Debug(ctx, "filesystem delete api", map[string]any{"retcode": retcode})
 
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
-	}
-
-	return nil
+	return waitForDeletion(ctx, "filesystem", DefaultFilesystemTimeout, func(ctx context.Context) (string, error) {
+		filesystem, err := client.GetFilesystemByResourceId(ctx, resourceId)
+		if err != nil {
+			return "", err
+		}
+		return filesystem.Status.Phase, nil
+	}, "FSDeleted")
 }
 
 func (client *IDCServicesClient) UpdateFilesystem(ctx context.Context, in *FilesystemUpdateRequest) error {
@@ -329,18 +313,14 @@ func (client *IDCServicesClient) UpdateFilesystem(ctx context.Context, in *Files
 	if err != nil {
 		return fmt.Errorf("error converting payload %v to JSON: %v", params.Payload, err)
 	}
-	tflog.Debug(ctx, "filesystem update api", map[string]any{"url": parsedURL, "payload byte": paramsByte})
+	tflog.Debug(ctx, "filesystem update api", map[string]any{"url": parsedURL})
 
-	retcode, retval, err := common.MakePutAPICall(ctx, parsedURL, *client.Apitoken, paramsByte)
+	retcode, _, err := client.callAPI(ctx, common.MakePutAPICall, parsedURL, paramsByte)
 	if err != nil {
-		return fmt.Errorf("error updating filesystem by name")
+		return fmt.Errorf("error updating filesystem by name: %w", err)
 	}
 
-	tflog.Debug(ctx, "filesystem update api", map[string]any{"retcode": retcode, "retval": string(retval), "error": err})
-
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
-	}
+	tflog.Debug(ctx, "filesystem update api", map[string]any{"retcode": retcode, "error": err})
 
 	return nil
 }
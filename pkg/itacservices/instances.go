@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"time"
 
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 
@@ -17,10 +15,8 @@ var (
 	getAllInstancesByAccount   = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/instances"
 	createInstance             = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/instances"
 	getInstanceByResourceId    = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/instances/id/{{.ResourceId}}"
+	updateInstanceByResourceId = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/instances/id/{{.ResourceId}}"
 	deleteInstanceByResourceId = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/instances/id/{{.ResourceId}}"
-
-	getAllVNetsByAccount = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets"
-	createVNetByAccount  = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets"
 )
 
 type Instances struct {
@@ -47,6 +43,7 @@ type Instance struct {
 		UserData            string   `json:"userData,omitempty"`
 		QuickConnectEnabled string   `json:"quickConnectEnabled,omitempty"`
 		QuickConnectUrl     string   `json:"quickConnectUrl,omitempty"`
+		RunStrategy         string   `json:"runStrategy,omitempty"`
 	} `json:"spec"`
 	Status struct {
 		Interfaces []struct {
@@ -74,47 +71,46 @@ type InstanceCreateRequest struct {
 		Name string `json:"name"`
 	} `json:"metadata"`
 	Spec struct {
-		AvailabilityZone string `json:"availabilityZone"`
-		InstanceGroup    string `json:"instanceGroup,omitempty"`
-		InstanceType     string `json:"instanceType"`
-		Interfaces       []struct {
-			Name string `json:"name"`
-			VNet string `json:"vNet"`
-		} `json:"interfaces"`
-		MachineImage        string   `json:"machineImage"`
-		SshPublicKeyNames   []string `json:"sshPublicKeyNames"`
-		UserData            string   `json:"userData,omitempty"`
-		QuickConnectEnabled string   `json:"quickConnectEnabled,omitempty"`
+		AvailabilityZone    string              `json:"availabilityZone"`
+		InstanceGroup       string              `json:"instanceGroup,omitempty"`
+		InstanceType        string              `json:"instanceType"`
+		Interfaces          []InstanceInterface `json:"interfaces"`
+		MachineImage        string              `json:"machineImage"`
+		SshPublicKeyNames   []string            `json:"sshPublicKeyNames"`
+		UserData            string              `json:"userData,omitempty"`
+		QuickConnectEnabled string              `json:"quickConnectEnabled,omitempty"`
 	} `json:"spec"`
 }
 
-type VNets struct {
-	Vnets []VNet `json:"items"`
+// InstanceInterface attaches an instance to a vnet of its availability zone.
+type InstanceInterface struct {
+	Name string `json:"name"`
+	VNet string `json:"vNet"`
 }
 
-type VNet struct {
-	Metadata struct {
-		ResourceId   string `json:"resourceId"`
-		Cloudaccount string `json:"cloudAccountId"`
-		Name         string `json:"name"`
-	} `json:"metadata"`
+// InstanceUpdateRequest holds the attributes of an instance that can change
+// in place.
+type InstanceUpdateRequest struct {
 	Spec struct {
-		AvailabilityZone string `json:"availabilityZone"`
-		Region           string `json:"region"`
-		PrefixLength     int64  `json:"prefixLength"`
-	}
+		SshPublicKeyNames   []string `json:"sshPublicKeyNames"`
+		UserData            string   `json:"userData"`
+		QuickConnectEnabled string   `json:"quickConnectEnabled,omitempty"`
+		RunStrategy         string   `json:"runStrategy,omitempty"`
+	} `json:"spec"`
 }
 
-type VNetCreateRequest struct {
-	Metadata struct {
-		Name string `json:"name"`
-	} `json:"metadata"`
-	Spec struct {
-		AvailabilityZone string `json:"availabilityZone"`
-		Region           string `json:"region"`
-		PrefixLength     int64  `json:"prefixLength"`
-	}
-}
+// Run strategies of an instance. An instance runs with RunStrategyAlways and
+// is stopped with RunStrategyHalted.
+const (
+	RunStrategyAlways = "Always"
+	RunStrategyHalted = "Halted"
+)
+
+// Phases of an instance that runs and of a stopped instance.
+const (
+	InstancePhaseReady   = "Ready"
+	InstancePhaseStopped = "Stopped"
+)
 
 func (client *IDCServicesClient) GetInstances(ctx context.Context) (*Instances, error) {
 	params := struct {
@@ -131,14 +127,10 @@ func (client *IDCServicesClient) GetInstances(ctx context.Context) (*Instances,
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
-	tflog.Debug(ctx, "instances read api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	tflog.Debug(ctx, "instances read api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading instances")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading instances: %w", err)
 	}
 
 	instances := Instances{}
@@ -148,6 +140,28 @@ func (client *IDCServicesClient) GetInstances(ctx context.Context) (*Instances,
 	return &instances, nil
 }
 
+// GetInstanceByName returns the instance named name, nil if there is none.
+func (client *IDCServicesClient) GetInstanceByName(ctx context.Context, name string) (*Instance, error) {
+	instances, err := client.GetInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range instances.Instances {
+		if instances.Instances[i].Metadata.Name == name {
+			return &instances.Instances[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Matches reports whether the instance is the one in would create, comparing
+// its instance type and machine image.
+func (instance *Instance) Matches(in *InstanceCreateRequest) bool {
+	return instance.Metadata.Name == in.Metadata.Name &&
+		instance.Spec.InstanceType == in.Spec.InstanceType &&
+		instance.Spec.MachineImage == in.Spec.MachineImage
+}
+
 func (client *IDCServicesClient) CreateInstance(ctx context.Context, in *InstanceCreateRequest, async bool) (*Instance, error) {
 	params := struct {
 		Host         string
@@ -168,47 +182,68 @@ func (client *IDCServicesClient) CreateInstance(ctx context.Context, in *Instanc
 		return nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "instance create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	// A create that may have reached the API is only sent again if it did
+	// not make the instance.
+	postCtx := common.WithResendCheck(ctx, func(ctx context.Context) ([]byte, error) {
+		instance, err := client.GetInstanceByName(ctx, in.Metadata.Name)
+		if err != nil || instance == nil || !instance.Matches(in) {
+			return nil, err
+		}
+		return json.Marshal(instance)
+	})
 
-	if err != nil {
-		return nil, fmt.Errorf("error reading instance create response")
-	}
-	tflog.Debug(ctx, "instance create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
+	tflog.Debug(ctx, "instance create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(postCtx, common.MakePOSTAPICall, parsedURL, inArgs)
 
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+	if err != nil {
+		return nil, fmt.Errorf("error reading instance create response: %w", err)
 	}
+	tflog.Debug(ctx, "instance create api response", map[string]any{"retcode": retcode})
 
 	instance := &Instance{}
 	if err := json.Unmarshal(retval, instance); err != nil {
 		return nil, fmt.Errorf("error parsing instance response")
 	}
 
-	if async {
-		instance, err = client.GetInstanceByResourceId(ctx, instance.Metadata.ResourceId)
+	if !async {
+		return client.WaitForInstance(ctx, instance.Metadata.ResourceId)
+	}
+	instance, err = client.GetInstanceByResourceId(ctx, instance.Metadata.ResourceId)
+	if err != nil {
+		return instance, fmt.Errorf("error reading instance state")
+	}
+	return instance, nil
+}
+
+// WaitForInstance polls the instance until it is ready. Errors carry the id
+// of the instance as a common.ProvisioningError.
+func (client *IDCServicesClient) WaitForInstance(ctx context.Context, resourceId string) (*Instance, error) {
+	return client.WaitForInstancePhase(ctx, resourceId, InstancePhaseReady)
+}
+
+// WaitForInstancePhase polls the instance until it reaches phase, such as
+// InstancePhaseStopped after it was stopped.
+func (client *IDCServicesClient) WaitForInstancePhase(ctx context.Context, resourceId, wantPhase string) (*Instance, error) {
+	var instance *Instance
+	var phase, message string
+	backoffTimer := waitBackoff(ctx, DefaultInstanceTimeout)
+
+	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
+		var err error
+		instance, err = client.GetInstanceByResourceId(ctx, resourceId)
 		if err != nil {
-			return instance, fmt.Errorf("error reading instance state")
+			return fmt.Errorf("error reading instance state: %w", err)
 		}
-	} else {
-		backoffTimer := retry.NewConstant(5 * time.Second)
-		backoffTimer = retry.WithMaxDuration(300*time.Second, backoffTimer)
-
-		if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
-			instance, err = client.GetInstanceByResourceId(ctx, instance.Metadata.ResourceId)
-			if err != nil {
-				return fmt.Errorf("error reading instance state")
-			}
-			if instance.Status.Phase == "Ready" {
-				return nil
-			} else if instance.Status.Phase == "Failed" {
-				return fmt.Errorf("instance state failed")
-			} else {
-				return retry.RetryableError(fmt.Errorf("instance state not ready, retry again"))
-			}
-		}); err != nil {
-			return nil, fmt.Errorf("instance state not ready after maximum retries")
+		phase, message = instance.Status.Phase, instance.Status.Message
+		if phase == wantPhase {
+			return nil
+		} else if phase == "Failed" {
+			return &common.ProvisioningError{Kind: "instance", ResourceId: resourceId, Phase: phase, Message: message}
+		} else {
+			return retry.RetryableError(fmt.Errorf("instance state not ready, retry again"))
 		}
+	}); err != nil {
+		return nil, provisioningError("instance", resourceId, phase, message, err)
 	}
 	return instance, nil
 }
@@ -230,13 +265,9 @@ func (client *IDCServicesClient) GetInstanceByResourceId(ctx context.Context, re
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading sshkey by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading instance by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "get instance api", map[string]any{"retcode": retcode})
@@ -247,7 +278,8 @@ func (client *IDCServicesClient) GetInstanceByResourceId(ctx context.Context, re
 	return &instance, nil
 }
 
-func (client *IDCServicesClient) DeleteInstanceByResourceId(ctx context.Context, resourceId string) error {
+// UpdateInstance changes the instance in place.
+func (client *IDCServicesClient) UpdateInstance(ctx context.Context, resourceId string, in *InstanceUpdateRequest) error {
 	params := struct {
 		Host         string
 		Cloudaccount string
@@ -259,101 +291,55 @@ func (client *IDCServicesClient) DeleteInstanceByResourceId(ctx context.Context,
 	}
 
 	// Parse the template string with the provided data
-	parsedURL, err := common.ParseString(deleteInstanceByResourceId, params)
+	parsedURL, err := common.ParseString(updateInstanceByResourceId, params)
 	if err != nil {
 		return fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	inArgs, err := json.MarshalIndent(in, "", "    ")
 	if err != nil {
-		return fmt.Errorf("error deleting sshkey by resource id")
+		return fmt.Errorf("error parsing input arguments")
 	}
 
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+	tflog.Debug(ctx, "instance update api request", map[string]any{"url": parsedURL})
+	retcode, _, err := client.callAPI(ctx, common.MakePutAPICall, parsedURL, inArgs)
+	if err != nil {
+		return fmt.Errorf("error updating instance by resource id: %w", err)
 	}
-
-	tflog.Debug(ctx, "instance delete api", map[string]any{"retcode": retcode})
+	tflog.Debug(ctx, "instance update api response", map[string]any{"retcode": retcode})
 
 	return nil
 }
 
-func (client *IDCServicesClient) CreateVNetIfNotFound(ctx context.Context) (*VNet, error) {
-
+func (client *IDCServicesClient) DeleteInstanceByResourceId(ctx context.Context, resourceId string) error {
 	params := struct {
 		Host         string
 		Cloudaccount string
+		ResourceId   string
 	}{
 		Host:         *client.Host,
 		Cloudaccount: *client.Cloudaccount,
+		ResourceId:   resourceId,
 	}
 
 	// Parse the template string with the provided data
-	parsedURL, err := common.ParseString(getAllVNetsByAccount, params)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing the url")
-	}
-	tflog.Debug(ctx, "vnets get api request", map[string]any{"url": parsedURL})
-
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
-
-	if err != nil || retcode != http.StatusOK {
-		tflog.Debug(ctx, "vnet get response", map[string]any{"retcode": retcode, "error": err})
-		return nil, fmt.Errorf("error reading vnets get response")
-	}
-
-	vnets := VNets{}
-	if err := json.Unmarshal(retval, &vnets); err != nil {
-		return nil, fmt.Errorf("error parsing instance response")
-	}
-	tflog.Debug(ctx, "vnets get api response", map[string]any{"retcode": retcode, "retval": vnets})
-
-	if len(vnets.Vnets) > 0 {
-		return &(vnets.Vnets[0]), nil
-	}
-
-	tflog.Debug(ctx, "vnets not found, creating a new")
-
-	inArgs := VNetCreateRequest{
-		Metadata: struct {
-			Name string "json:\"name\""
-		}{
-			Name: "us-staging-1a-default",
-		},
-		Spec: struct {
-			AvailabilityZone string "json:\"availabilityZone\""
-			Region           string "json:\"region\""
-			PrefixLength     int64  "json:\"prefixLength\""
-		}{
-			AvailabilityZone: "us-staging-1a",
-			Region:           "us-staging-1",
-			PrefixLength:     24,
-		},
-	}
-
-	payload, err := json.MarshalIndent(inArgs, "", "    ")
+	parsedURL, err := common.ParseString(deleteInstanceByResourceId, params)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing input arguments")
+		return fmt.Errorf("error parsing the url")
 	}
 
-	// Parse the template string with the provided data
-	parsedURL, err = common.ParseString(createVNetByAccount, params)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing the url")
-	}
-
-	retcode, retval, err = common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, payload)
-
-	if err != nil || retcode != http.StatusOK {
-		return nil, fmt.Errorf("error reading vnet create response")
+		return fmt.Errorf("error deleting instance by resource id: %w", err)
 	}
 
-	vnet := VNet{}
-	if err := json.Unmarshal(retval, &vnet); err != nil {
-		return nil, fmt.Errorf("error parsing vnet response")
-	}
-	tflog.Debug(ctx, "vnet create api response", map[string]any{"retcode": retcode, "retval": vnet})
-
-	return &vnet, nil
+	tflog.Debug(ctx, "instance delete api", map[string]any{"retcode": retcode})
 
+	return waitForDeletion(ctx, "instance", DefaultInstanceTimeout, func(ctx context.Context) (string, error) {
+		instance, err := client.GetInstanceByResourceId(ctx, resourceId)
+		if err != nil {
+			return "", err
+		}
+		return instance.Status.Phase, nil
+	})
 }
@@ -0,0 +1,92 @@
+package itacservices
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+)
+
+func TestCreateInstanceResend(t *testing.T) {
+	const instancesPath = "/v1/cloudaccounts/" + testCloudaccount + "/instances"
+	const created = `{"metadata": {"name": "vm1", "resourceId": "i1"}, "spec": {"instanceType": "vm-spr-sml", "machineImage": "ubuntu"}, "status": {"phase": "Provisioning"}}`
+
+	tests := []struct {
+		name    string
+		steps   []apiStep
+		wantID  string
+		wantErr func(error) bool
+	}{
+		{
+			name: "instance created without an answer",
+			steps: []apiStep{
+				{method: http.MethodPost, path: instancesPath, status: http.StatusGatewayTimeout, response: errorBody(4, "timeout")},
+				{method: http.MethodGet, path: instancesPath, status: http.StatusOK, response: `{"items": [` + created + `]}`},
+				{method: http.MethodGet, path: instancesPath + "/id/i1", status: http.StatusOK, response: created},
+			},
+			wantID: "i1",
+		},
+		{
+			name: "instance not created",
+			steps: []apiStep{
+				{method: http.MethodPost, path: instancesPath, status: http.StatusServiceUnavailable, response: errorBody(14, "unavailable")},
+				{method: http.MethodGet, path: instancesPath, status: http.StatusOK, response: `{"items": []}`},
+				{method: http.MethodPost, path: instancesPath, status: http.StatusOK, response: created},
+				{method: http.MethodGet, path: instancesPath + "/id/i1", status: http.StatusOK, response: created},
+			},
+			wantID: "i1",
+		},
+		{
+			name: "another instance with the name",
+			steps: []apiStep{
+				{method: http.MethodPost, path: instancesPath, status: http.StatusGatewayTimeout, response: errorBody(4, "timeout")},
+				{method: http.MethodGet, path: instancesPath, status: http.StatusOK,
+					response: `{"items": [{"metadata": {"name": "vm1", "resourceId": "i0"}, "spec": {"instanceType": "bm-spr"}}]}`},
+				{method: http.MethodPost, path: instancesPath, status: http.StatusConflict, response: errorBody(6, "exists")},
+			},
+			wantErr: isError[*common.ConflictError],
+		},
+		{
+			name: "lookup forbidden",
+			steps: []apiStep{
+				{method: http.MethodPost, path: instancesPath, status: http.StatusGatewayTimeout, response: errorBody(4, "timeout")},
+				{method: http.MethodGet, path: instancesPath, status: http.StatusForbidden, response: errorBody(7, "denied")},
+			},
+			wantErr: isError[*common.ForbiddenError],
+		},
+		{
+			name: "rejected create not sent again",
+			steps: []apiStep{
+				{method: http.MethodPost, path: instancesPath, status: http.StatusConflict, response: errorBody(6, "exists")},
+			},
+			wantErr: isError[*common.ConflictError],
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-staging-1", tc.steps)
+			client.RetryPolicy.MaxRetries = 1
+
+			in := &InstanceCreateRequest{}
+			in.Metadata.Name = "vm1"
+			in.Spec.InstanceType = "vm-spr-sml"
+			in.Spec.MachineImage = "ubuntu"
+			instance, err := client.CreateInstance(context.Background(), in, true)
+			script.done()
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if instance.Metadata.ResourceId != tc.wantID {
+				t.Errorf("instance id = %q, want %q", instance.Metadata.ResourceId, tc.wantID)
+			}
+		})
+	}
+}
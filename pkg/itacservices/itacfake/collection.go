@@ -0,0 +1,264 @@
+package itacfake
+
+import (
+	"time"
+)
+
+// lifecycle names the phases a kind of resource moves through and where the
+// phase is kept in its document.
+type lifecycle struct {
+	kind         Kind
+	provisioning string
+	ready        string
+	failed       string
+	deleting     string
+	phaseField   func(doc map[string]any) (map[string]any, string)
+	// messageField, if set, is where the reason of a failure is kept.
+	messageField func(doc map[string]any) (map[string]any, string)
+}
+
+// FailureMessage is the message of resources moved to their failed phase by
+// PhaseFail, for kinds that report one.
+const FailureMessage = "insufficient capacity for the requested instance type"
+
+// statusPhase keeps the phase in status.phase.
+func statusPhase(doc map[string]any) (map[string]any, string) {
+	return child(doc, "status"), "phase"
+}
+
+// statusMessage keeps the message in status.message.
+func statusMessage(doc map[string]any) (map[string]any, string) {
+	return child(doc, "status"), "message"
+}
+
+// nested keeps a field in the object under key.
+func nested(key, field string) func(doc map[string]any) (map[string]any, string) {
+	return func(doc map[string]any) (map[string]any, string) {
+		return child(doc, key), field
+	}
+}
+
+// topLevel keeps the phase in a top-level field of the document.
+func topLevel(field string) func(doc map[string]any) (map[string]any, string) {
+	return func(doc map[string]any) (map[string]any, string) {
+		return doc, field
+	}
+}
+
+var (
+	instanceLifecycle   = lifecycle{KindInstance, "Provisioning", "Ready", "Failed", "Terminating", statusPhase, statusMessage}
+	filesystemLifecycle = lifecycle{KindFilesystem, "FSProvisioning", "FSReady", "FSFailed", "FSDeleting", statusPhase, nil}
+	bucketLifecycle     = lifecycle{KindBucket, "BucketProvisioning", "BucketReady", "BucketFailed", "BucketDeleting", statusPhase, nil}
+	clusterLifecycle    = lifecycle{KindCluster, "Pending", "Active", "Failed", "Deleting", topLevel("clusterstate"), nested("clusterstatus", "message")}
+	nodeGroupLifecycle  = lifecycle{KindNodeGroup, "Creating", "Active", "Failed", "Deleting", topLevel("nodegroupstate"), nested("nodegroupstatus", "message")}
+	storageLifecycle    = lifecycle{KindStorage, "Creating", "Active", "Failed", "Deleting", topLevel("state"), nil}
+	vipLifecycle        = lifecycle{KindLoadBalancer, "Pending", "Active", "Failed", "Deleting", topLevel("vipstate"), nil}
+)
+
+// object is a stored resource.
+type object struct {
+	doc map[string]any
+	// readyAt is when the resource leaves its provisioning phase.
+	readyAt time.Time
+	// goneAt is when a deleted resource disappears, zero if not deleted.
+	goneAt time.Time
+	// halted is set on instances stopped by their run strategy, started on
+	// those started again.
+	halted  bool
+	started bool
+}
+
+// Phases of instances stopped and started by their run strategy.
+const (
+	phaseStopping = "Stopping"
+	phaseStopped  = "Stopped"
+	phaseStarting = "Starting"
+)
+
+// collection holds the resources of one kind in creation order.
+type collection struct {
+	lifecycle *lifecycle
+	// behaviors is shared with the server and keyed by resource name, the
+	// empty name applying to every resource of the kind.
+	behaviors map[string]PhaseBehavior
+	items     map[string]*object
+	order     []string
+}
+
+func newCollection(lc *lifecycle, behaviors map[string]PhaseBehavior) *collection {
+	return &collection{lifecycle: lc, behaviors: behaviors, items: map[string]*object{}}
+}
+
+// add stores doc under id, starting its provisioning phase at now.
+func (c *collection) add(id string, doc map[string]any, now time.Time, delay time.Duration) *object {
+	obj := &object{doc: doc, readyAt: now.Add(delay)}
+	c.items[id] = obj
+	c.order = append(c.order, id)
+	c.refresh(id, now)
+	return obj
+}
+
+// restart puts a resource back into its provisioning phase, as done by an
+// upgrade.
+func (c *collection) restart(id string, now time.Time, delay time.Duration) {
+	if obj, ok := c.items[id]; ok {
+		obj.readyAt = now.Add(delay)
+		c.refresh(id, now)
+	}
+}
+
+// setHalted stops or starts a resource, which reaches its stopped or ready
+// phase after delay.
+func (c *collection) setHalted(id string, halted bool, now time.Time, delay time.Duration) {
+	obj, ok := c.items[id]
+	if !ok || obj.halted == halted {
+		return
+	}
+	obj.halted = halted
+	obj.started = !halted
+	obj.readyAt = now.Add(delay)
+	c.refresh(id, now)
+}
+
+// get returns the resource with its phase brought up to date, or false if it
+// does not exist or is gone.
+func (c *collection) get(id string, now time.Time) (*object, bool) {
+	if !c.refresh(id, now) {
+		return nil, false
+	}
+	return c.items[id], true
+}
+
+// list returns the documents of all existing resources.
+func (c *collection) list(now time.Time) []map[string]any {
+	docs := []map[string]any{}
+	for _, id := range append([]string(nil), c.order...) {
+		if obj, ok := c.get(id, now); ok {
+			docs = append(docs, obj.doc)
+		}
+	}
+	return docs
+}
+
+// remove deletes a resource, immediately if its kind has no lifecycle and
+// after delay otherwise. It returns false if the resource does not exist.
+func (c *collection) remove(id string, now time.Time, delay time.Duration) bool {
+	obj, ok := c.get(id, now)
+	if !ok {
+		return false
+	}
+	if c.lifecycle == nil || delay <= 0 {
+		c.drop(id)
+		return true
+	}
+	if obj.goneAt.IsZero() {
+		obj.goneAt = now.Add(delay)
+	}
+	c.refresh(id, now)
+	return true
+}
+
+// refresh updates the phase of a resource from the clock and drops it once
+// deleted. It returns false if the resource does not exist anymore.
+func (c *collection) refresh(id string, now time.Time) bool {
+	obj, ok := c.items[id]
+	if !ok {
+		return false
+	}
+	if !obj.goneAt.IsZero() && !now.Before(obj.goneAt) {
+		c.drop(id)
+		return false
+	}
+	if c.lifecycle == nil {
+		return true
+	}
+
+	phase, ready := c.lifecycle.provisioning, c.lifecycle.ready
+	if obj.halted {
+		phase, ready = phaseStopping, phaseStopped
+	} else if obj.started {
+		phase = phaseStarting
+	}
+	behavior := c.behavior(obj.doc)
+	switch {
+	case !obj.goneAt.IsZero():
+		phase = c.lifecycle.deleting
+	case behavior == PhaseStuck:
+	case !now.Before(obj.readyAt) && behavior == PhaseFail:
+		phase = c.lifecycle.failed
+	case !now.Before(obj.readyAt):
+		phase = ready
+	}
+	doc, field := c.lifecycle.phaseField(obj.doc)
+	doc[field] = phase
+	if c.lifecycle.messageField != nil {
+		message := ""
+		if phase == c.lifecycle.failed {
+			message = FailureMessage
+		}
+		doc, field := c.lifecycle.messageField(obj.doc)
+		doc[field] = message
+	}
+	return true
+}
+
+// behavior returns the phase behavior set for the resource doc.
+func (c *collection) behavior(doc map[string]any) PhaseBehavior {
+	name := stringAt(doc, "metadata", "name")
+	if name == "" {
+		name = stringAt(doc, "name")
+	}
+	if b, ok := c.behaviors[name]; ok {
+		return b
+	}
+	return c.behaviors[""]
+}
+
+func (c *collection) drop(id string) {
+	delete(c.items, id)
+	for i, v := range c.order {
+		if v == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// findByField returns the id of the first existing resource whose document
+// has value at the given path, such as metadata.name.
+func (c *collection) findByField(now time.Time, value string, path ...string) (string, bool) {
+	for _, id := range append([]string(nil), c.order...) {
+		obj, ok := c.get(id, now)
+		if !ok {
+			continue
+		}
+		if stringAt(obj.doc, path...) == value {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// child returns the object stored under key, creating it if needed.
+func child(doc map[string]any, key string) map[string]any {
+	if m, ok := doc[key].(map[string]any); ok {
+		return m
+	}
+	m := map[string]any{}
+	doc[key] = m
+	return m
+}
+
+// stringAt returns the string at the given path of doc, or "".
+func stringAt(doc map[string]any, path ...string) string {
+	var cur any = doc
+	for _, key := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return ""
+		}
+		cur = m[key]
+	}
+	s, _ := cur.(string)
+	return s
+}
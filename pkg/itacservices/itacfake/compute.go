@@ -0,0 +1,271 @@
+package itacfake
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+func (s *Server) now() time.Time {
+	return s.cfg.Clock.Now()
+}
+
+// nextIP returns a fresh address in the fake 10.0.0.0/16 subnet.
+func (s *Server) nextIP() string {
+	s.ipCounter++
+	return fmt.Sprintf("10.0.%d.%d", s.ipCounter/250, s.ipCounter%250+2)
+}
+
+// handleInstances serves
+//
+//	/instances
+//	/instances/id/{id}
+func (s *Server) handleInstances(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"items": s.instances.list(s.now())})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.createInstance(w, r, account)
+	case len(rest) == 2 && rest[0] == "id" && r.Method == http.MethodPut:
+		s.updateInstance(w, r, rest[1])
+	case len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.instances, rest[1], "instance")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func (s *Server) createInstance(w http.ResponseWriter, r *http.Request, account string) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	name := stringAt(doc, "metadata", "name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, 3, "metadata.name is required")
+		return
+	}
+	if _, exists := s.instances.findByField(s.now(), name, "metadata", "name"); exists {
+		writeError(w, http.StatusConflict, 6, "instance "+name+" already exists")
+		return
+	}
+	spec := child(doc, "spec")
+	if t := stringAt(spec, "instanceType"); !slices.Contains(s.cfg.InstanceTypes, t) {
+		writeError(w, http.StatusBadRequest, 3, "unknown instance type "+t)
+		return
+	}
+	if img := stringAt(spec, "machineImage"); !slices.Contains(s.cfg.MachineImages, img) {
+		writeError(w, http.StatusBadRequest, 3, "unknown machine image "+img)
+		return
+	}
+	keys, _ := spec["sshPublicKeyNames"].([]any)
+	for _, k := range keys {
+		keyName, _ := k.(string)
+		if _, ok := s.sshkeys.findByField(s.now(), keyName, "metadata", "name"); !ok {
+			writeError(w, http.StatusBadRequest, 3, "unknown ssh public key "+keyName)
+			return
+		}
+	}
+
+	id := newID()
+	statusInterfaces := []any{}
+	interfaces, _ := spec["interfaces"].([]any)
+	for _, i := range interfaces {
+		iface, _ := i.(map[string]any)
+		vnet := stringAt(iface, "vNet")
+		if vnet == "" {
+			vnet = stringAt(iface, "vnet")
+		}
+		vnetID, ok := s.vnets.findByField(s.now(), vnet, "metadata", "name")
+		if !ok {
+			writeError(w, http.StatusBadRequest, 3, "unknown vnet "+vnet)
+			return
+		}
+		vnetObj, _ := s.vnets.get(vnetID, s.now())
+		if zone := stringAt(vnetObj.doc, "spec", "availabilityZone"); zone != stringAt(spec, "availabilityZone") {
+			writeError(w, http.StatusBadRequest, 3, "vnet "+vnet+" is not in availability zone "+stringAt(spec, "availabilityZone"))
+			return
+		}
+		iface["vnet"] = vnet
+		delete(iface, "vNet")
+		statusInterfaces = append(statusInterfaces, map[string]any{
+			"addresses":    []string{s.nextIP()},
+			"dnsName":      name + "." + account + ".fake.intelcloud",
+			"gateway":      "10.0.0.1",
+			"name":         stringAt(iface, "name"),
+			"prefixLength": 16,
+			"subnet":       "10.0.0.0",
+			"vNet":         vnet,
+		})
+	}
+
+	doc["metadata"] = map[string]any{
+		"resourceId":        id,
+		"cloudAccountId":    account,
+		"name":              name,
+		"creationTimestamp": s.now().UTC().Format(time.RFC3339),
+	}
+	doc["status"] = map[string]any{
+		"message":    "",
+		"interfaces": statusInterfaces,
+		"sshProxy": map[string]any{
+			"proxyAddress": "ssh.fake.intelcloud",
+			"proxyPort":    22,
+			"proxyUser":    "guest",
+		},
+		"userName": "ubuntu",
+	}
+	obj := s.instances.add(id, doc, s.now(), s.cfg.ProvisioningDelay)
+	writeJSON(w, http.StatusOK, obj.doc)
+}
+
+// updateInstance replaces the ssh keys, user data and quick connect setting
+// of an instance, and stops or starts it as set by its run strategy.
+func (s *Server) updateInstance(w http.ResponseWriter, r *http.Request, id string) {
+	obj, ok := s.instances.get(id, s.now())
+	if !ok {
+		writeError(w, http.StatusNotFound, 5, "instance "+id+" not found")
+		return
+	}
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	update := child(doc, "spec")
+	keys, _ := update["sshPublicKeyNames"].([]any)
+	for _, k := range keys {
+		keyName, _ := k.(string)
+		if _, ok := s.sshkeys.findByField(s.now(), keyName, "metadata", "name"); !ok {
+			writeError(w, http.StatusBadRequest, 3, "unknown ssh public key "+keyName)
+			return
+		}
+	}
+
+	runStrategy := stringAt(update, "runStrategy")
+	if runStrategy != "" && !slices.Contains([]string{"Always", "Halted", "RerunOnFailure"}, runStrategy) {
+		writeError(w, http.StatusBadRequest, 3, "unknown run strategy "+runStrategy)
+		return
+	}
+
+	spec := child(obj.doc, "spec")
+	spec["sshPublicKeyNames"] = keys
+	spec["userData"] = stringAt(update, "userData")
+	if quickConnect := stringAt(update, "quickConnectEnabled"); quickConnect != "" {
+		spec["quickConnectEnabled"] = quickConnect
+	}
+	if runStrategy != "" {
+		spec["runStrategy"] = runStrategy
+		s.instances.setHalted(id, runStrategy == "Halted", s.now(), s.cfg.ProvisioningDelay)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+// handleVNets serves
+//
+//	/vnets
+//	/vnets/id/{id}
+func (s *Server) handleVNets(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"items": s.vnets.list(s.now())})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		name := stringAt(doc, "metadata", "name")
+		if name == "" {
+			writeError(w, http.StatusBadRequest, 3, "metadata.name is required")
+			return
+		}
+		spec := child(doc, "spec")
+		if stringAt(spec, "availabilityZone") == "" {
+			writeError(w, http.StatusBadRequest, 3, "spec.availabilityZone is required")
+			return
+		}
+		if prefixLength, _ := spec["prefixLength"].(float64); prefixLength < 16 || prefixLength > 29 {
+			writeError(w, http.StatusBadRequest, 3, "spec.prefixLength must be between 16 and 29")
+			return
+		}
+		if _, exists := s.vnets.findByField(s.now(), name, "metadata", "name"); exists {
+			writeError(w, http.StatusConflict, 6, "vnet "+name+" already exists")
+			return
+		}
+		id := newID()
+		doc["metadata"] = map[string]any{
+			"resourceId":     id,
+			"cloudAccountId": account,
+			"name":           name,
+		}
+		obj := s.vnets.add(id, doc, s.now(), 0)
+		writeJSON(w, http.StatusOK, obj.doc)
+	case len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.vnets, rest[1], "vnet")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+// handleSSHKeys serves
+//
+//	/sshpublickeys
+//	/sshpublickeys/id/{id}
+func (s *Server) handleSSHKeys(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"items": s.sshkeys.list(s.now())})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		name := stringAt(doc, "metadata", "name")
+		if name == "" || stringAt(doc, "spec", "sshPublicKey") == "" {
+			writeError(w, http.StatusBadRequest, 3, "metadata.name and spec.sshPublicKey are required")
+			return
+		}
+		if _, exists := s.sshkeys.findByField(s.now(), name, "metadata", "name"); exists {
+			writeError(w, http.StatusConflict, 6, "ssh public key "+name+" already exists")
+			return
+		}
+		id := newID()
+		doc["metadata"] = map[string]any{
+			"resourceId":     id,
+			"cloudAccountId": account,
+			"name":           name,
+			"description":    stringAt(doc, "metadata", "description"),
+		}
+		obj := s.sshkeys.add(id, doc, s.now(), 0)
+		writeJSON(w, http.StatusOK, obj.doc)
+	case len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.sshkeys, rest[1], "ssh public key")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+// handleByID serves GET and DELETE of a single resource of c.
+func (s *Server) handleByID(w http.ResponseWriter, r *http.Request, c *collection, id, kind string) {
+	switch r.Method {
+	case http.MethodGet:
+		obj, ok := c.get(id, s.now())
+		if !ok {
+			writeError(w, http.StatusNotFound, 5, kind+" "+id+" not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, obj.doc)
+	case http.MethodDelete:
+		if !c.remove(id, s.now(), s.cfg.DeletionDelay) {
+			writeError(w, http.StatusNotFound, 5, kind+" "+id+" not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+	}
+}
@@ -0,0 +1,266 @@
+package itacfake
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// handleIKS serves
+//
+//	/iks/clusters
+//	/iks/clusters/{uuid}
+//	/iks/clusters/{uuid}/nodegroups
+//	/iks/clusters/{uuid}/nodegroups/{nodegroupuuid}
+//	/iks/clusters/{uuid}/kubeconfig
+//	/iks/clusters/{uuid}/upgrade
+//	/iks/clusters/{uuid}/storage
+//	/iks/clusters/{uuid}/vips
+//	/iks/clusters/{uuid}/vips/{vipid}
+func (s *Server) handleIKS(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	if len(rest) == 0 || rest[0] != "clusters" {
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+		return
+	}
+	rest = rest[1:]
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		clusters := []map[string]any{}
+		for _, doc := range s.clusters.list(s.now()) {
+			clusters = append(clusters, s.clusterView(stringAt(doc, "uuid"), doc))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"clusters": clusters})
+		return
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.createCluster(w, r)
+		return
+	case len(rest) == 0:
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+		return
+	}
+
+	uuid := rest[0]
+	cluster, ok := s.clusters.get(uuid, s.now())
+	if !ok {
+		writeError(w, http.StatusNotFound, 5, "cluster "+uuid+" not found")
+		return
+	}
+	rest = rest[1:]
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.clusterView(uuid, cluster.doc))
+	case len(rest) == 0 && r.Method == http.MethodDelete:
+		s.clusters.remove(uuid, s.now(), s.cfg.DeletionDelay)
+		writeJSON(w, http.StatusOK, map[string]any{})
+	case rest[0] == "nodegroups":
+		s.handleNodeGroups(w, r, uuid, rest[1:])
+	case len(rest) == 1 && rest[0] == "kubeconfig" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{
+			"clusterid":  uuid,
+			"kubeconfig": fakeKubeconfig(stringAt(cluster.doc, "name")),
+		})
+	case len(rest) == 1 && rest[0] == "upgrade" && r.Method == http.MethodPost:
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		if v := stringAt(doc, "k8sversionname"); v != "" {
+			cluster.doc["k8sversion"] = v
+		}
+		s.clusters.restart(uuid, s.now(), s.cfg.ProvisioningDelay)
+		writeJSON(w, http.StatusOK, s.clusterView(uuid, cluster.doc))
+	case len(rest) == 1 && rest[0] == "storage" && r.Method == http.MethodPost:
+		s.createStorage(w, r, uuid, cluster)
+	case rest[0] == "vips":
+		s.handleVIPs(w, r, uuid, rest[1:])
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+// clusterView returns the cluster document with its node groups, storages
+// and load balancers embedded, as returned by the real service.
+func (s *Server) clusterView(uuid string, doc map[string]any) map[string]any {
+	doc["nodegroups"] = s.children(s.nodeGroups, &nodeGroupLifecycle, uuid).list(s.now())
+	doc["storages"] = s.children(s.storages, &storageLifecycle, uuid).list(s.now())
+	doc["vips"] = s.children(s.vips, &vipLifecycle, uuid).list(s.now())
+	return doc
+}
+
+// children returns the child collection of cluster uuid in m, creating it
+// with lifecycle lc if needed.
+func (s *Server) children(m map[string]*collection, lc *lifecycle, uuid string) *collection {
+	c, ok := m[uuid]
+	if !ok {
+		c = newCollection(lc, s.behaviors[lc.kind])
+		m[uuid] = c
+	}
+	return c
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	name := stringAt(doc, "name")
+	version := stringAt(doc, "k8sversionname")
+	if name == "" || version == "" {
+		writeError(w, http.StatusBadRequest, 3, "name and k8sversionname are required")
+		return
+	}
+	if _, exists := s.clusters.findByField(s.now(), name, "name"); exists {
+		writeError(w, http.StatusConflict, 6, "cluster "+name+" already exists")
+		return
+	}
+
+	uuid := "cl-" + newID()[:10]
+	cluster := map[string]any{
+		"uuid":                       uuid,
+		"name":                       name,
+		"description":                stringAt(doc, "description"),
+		"createddate":                s.now().UTC().Format(time.RFC3339),
+		"k8sversion":                 version,
+		"upgradeavailable":           false,
+		"upgradek8sversionavailable": []string{},
+		"network": map[string]any{
+			"enableloadbalancer": false,
+			"servicecidr":        "100.66.0.0/16",
+			"clustercidr":        "100.68.0.0/16",
+			"clusterdns":         "100.66.0.10",
+		},
+		"storageenabled": false,
+	}
+	obj := s.clusters.add(uuid, cluster, s.now(), s.cfg.ProvisioningDelay)
+	writeJSON(w, http.StatusOK, s.clusterView(uuid, obj.doc))
+}
+
+// handleNodeGroups serves the node groups of cluster uuid.
+func (s *Server) handleNodeGroups(w http.ResponseWriter, r *http.Request, uuid string, rest []string) {
+	groups := s.children(s.nodeGroups, &nodeGroupLifecycle, uuid)
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		name := stringAt(doc, "name")
+		if name == "" {
+			writeError(w, http.StatusBadRequest, 3, "name is required")
+			return
+		}
+		if _, exists := groups.findByField(s.now(), name, "name"); exists {
+			writeError(w, http.StatusConflict, 6, "node group "+name+" already exists")
+			return
+		}
+		id := "ng-" + newID()[:10]
+		count, _ := doc["count"].(float64)
+		vnets, _ := doc["vnets"].([]any)
+		iface := ""
+		if len(vnets) > 0 {
+			if v, ok := vnets[0].(map[string]any); ok {
+				iface = stringAt(v, "networkinterfacevnetname")
+			}
+		}
+		group := map[string]any{
+			"nodegroupuuid":        id,
+			"name":                 name,
+			"count":                int64(count),
+			"instancetypeid":       stringAt(doc, "instancetypeid"),
+			"sshkeyname":           doc["sshkeyname"],
+			"networkinterfacename": iface,
+			"imiid":                "iks-" + stringAt(doc, "instancetypeid"),
+			"userdataurl":          stringAt(doc, "userdataurl"),
+			"vnets":                vnets,
+		}
+		obj := groups.add(id, group, s.now(), s.cfg.ProvisioningDelay)
+		writeJSON(w, http.StatusOK, obj.doc)
+	case len(rest) == 1:
+		s.handleByID(w, r, groups, rest[0], "node group")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func (s *Server) createStorage(w http.ResponseWriter, r *http.Request, uuid string, cluster *object) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	size := stringAt(doc, "storagesize")
+	if size == "" {
+		writeError(w, http.StatusBadRequest, 3, "storagesize is required")
+		return
+	}
+	enabled, _ := doc["enablestorage"].(bool)
+	cluster.doc["storageenabled"] = enabled
+
+	storage := map[string]any{
+		"storageprovider": "weka",
+		"size":            size,
+	}
+	obj := s.children(s.storages, &storageLifecycle, uuid).add(newID(), storage, s.now(), s.cfg.ProvisioningDelay)
+	writeJSON(w, http.StatusOK, obj.doc)
+}
+
+// handleVIPs serves the load balancers of cluster uuid.
+func (s *Server) handleVIPs(w http.ResponseWriter, r *http.Request, uuid string, rest []string) {
+	vips := s.children(s.vips, &vipLifecycle, uuid)
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"response": vips.list(s.now())})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		name := stringAt(doc, "name")
+		port, _ := doc["port"].(float64)
+		if name == "" || port == 0 {
+			writeError(w, http.StatusBadRequest, 3, "name and port are required")
+			return
+		}
+		id := s.nextVipID
+		s.nextVipID++
+		vip := map[string]any{
+			"vipid":    id,
+			"name":     name,
+			"port":     int64(port),
+			"viptype":  stringAt(doc, "viptype"),
+			"vipip":    s.nextIP(),
+			"poolport": 30000 + id,
+		}
+		obj := vips.add(strconv.FormatInt(id, 10), vip, s.now(), s.cfg.ProvisioningDelay)
+		writeJSON(w, http.StatusOK, obj.doc)
+	case len(rest) == 1:
+		s.handleByID(w, r, vips, rest[0], "load balancer")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func fakeKubeconfig(name string) string {
+	return "apiVersion: v1\n" +
+		"kind: Config\n" +
+		"clusters:\n" +
+		"- name: " + name + "\n" +
+		"  cluster:\n" +
+		"    server: https://" + name + ".iks.fake.intelcloud:6443\n" +
+		"contexts:\n" +
+		"- name: " + name + "\n" +
+		"  context:\n" +
+		"    cluster: " + name + "\n" +
+		"    user: admin\n" +
+		"current-context: " + name + "\n" +
+		"users:\n" +
+		"- name: admin\n" +
+		"  user:\n" +
+		"    token: fake\n"
+}
@@ -0,0 +1,187 @@
+package itacfake
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind names a kind of resource that moves through provisioning phases.
+type Kind string
+
+const (
+	KindInstance     Kind = "instance"
+	KindFilesystem   Kind = "filesystem"
+	KindBucket       Kind = "bucket"
+	KindCluster      Kind = "cluster"
+	KindNodeGroup    Kind = "nodegroup"
+	KindStorage      Kind = "storage"
+	KindLoadBalancer Kind = "loadbalancer"
+)
+
+// PhaseBehavior controls how a resource leaves its provisioning phase.
+type PhaseBehavior int
+
+const (
+	// PhaseNormal moves the resource to its ready phase once the
+	// provisioning delay has passed.
+	PhaseNormal PhaseBehavior = iota
+	// PhaseStuck keeps the resource in its provisioning phase.
+	PhaseStuck
+	// PhaseFail moves the resource to its failed phase, such as Failed or
+	// FSFailed, once the provisioning delay has passed.
+	PhaseFail
+)
+
+// SetPhaseBehavior sets how resources of kind named name move through their
+// phases. An empty name applies to every resource of the kind without a
+// behavior of its own; storages have no name and only match the empty name.
+// The behavior also applies to resources that already exist.
+func (s *Server) SetPhaseBehavior(kind Kind, name string, b PhaseBehavior) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.behaviors[kind][name] = b
+}
+
+// Fault is a failure injected into the requests it matches, including those
+// to the token endpoint.
+type Fault struct {
+	// Method matches the request method. Empty matches every method.
+	Method string
+	// Path matches the request path with path.Match, for example
+	// "/v1/cloudaccounts/*/instances/id/*". Empty matches every path.
+	Path string
+	// Latency delays the response.
+	Latency time.Duration
+	// Status answers the request with this status and an error body
+	// instead of serving it.
+	Status int
+	// RetryAfter is sent as the Retry-After header, in seconds, with
+	// Status.
+	RetryAfter int
+	// LoseResponse serves the request before answering it with Status, as
+	// a gateway timing out on a request the API processed.
+	LoseResponse bool
+	// MalformedJSON answers the request with 200 and a body that is not
+	// valid JSON instead of serving it.
+	MalformedJSON bool
+	// Times limits the fault to the first Times matching requests. Zero
+	// applies it to every matching request.
+	Times int
+}
+
+type activeFault struct {
+	Fault
+	hits int
+}
+
+// InjectFault adds f to the faults of the server. When several faults match
+// a request the one injected first applies.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &activeFault{Fault: f})
+}
+
+// ClearFaults removes every injected fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// RequestCount returns how many requests matching method and the path
+// pattern the server has received, with the same matching as Fault.
+func (s *Server) RequestCount(method, pattern string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, req := range s.requests {
+		m, p, _ := strings.Cut(req, " ")
+		if matches(method, pattern, m, p) {
+			n++
+		}
+	}
+	return n
+}
+
+// matchFault returns the fault applying to r and counts the hit, or the zero
+// Fault if there is none.
+func (s *Server) matchFault(r *http.Request) Fault {
+	for _, f := range s.faults {
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		if matches(f.Method, f.Path, r.Method, r.URL.Path) {
+			f.hits++
+			return f.Fault
+		}
+	}
+	return Fault{}
+}
+
+// apply delays and answers r as set by the fault. It returns true if the
+// request has been answered.
+func (f Fault) apply(w http.ResponseWriter, r *http.Request) bool {
+	if f.Latency > 0 {
+		t := time.NewTimer(f.Latency)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			return true
+		}
+	}
+
+	switch {
+	case f.Status != 0:
+		if f.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(f.RetryAfter))
+		}
+		writeError(w, f.Status, grpcCode(f.Status), "injected fault")
+		return true
+	case f.MalformedJSON:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"metadata": {"resourceId": `))
+		return true
+	}
+	return false
+}
+
+func matches(method, pattern, reqMethod, reqPath string) bool {
+	if method != "" && method != reqMethod {
+		return false
+	}
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, reqPath)
+	return ok
+}
+
+// grpcCode returns the gRPC status code the API uses for an HTTP status.
+func grpcCode(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return 3
+	case http.StatusUnauthorized:
+		return 16
+	case http.StatusForbidden:
+		return 7
+	case http.StatusNotFound:
+		return 5
+	case http.StatusConflict:
+		return 6
+	case http.StatusTooManyRequests:
+		return 8
+	case http.StatusServiceUnavailable:
+		return 14
+	case http.StatusGatewayTimeout:
+		return 4
+	default:
+		return 13
+	}
+}
@@ -0,0 +1,348 @@
+// Package itacfake provides an in-process fake of the Intel Cloud API for
+// offline tests. It serves the token endpoint and every API route used by
+// the itacservices client, keeps resources in memory and moves them through
+// their provisioning phases as its clock advances. Tests can inject faults
+// into the routes and make resources stick in or fail their provisioning
+// phase to exercise error handling.
+package itacfake
+
+import (
+	"crypto/rand"
+	b64 "encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock tells the fake server the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// ManualClock is a Clock that only moves when advanced, so tests control
+// exactly when resources leave their provisioning phase.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock returns a ManualClock set to start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Config configures a fake server. The zero value is usable.
+type Config struct {
+	// Clock drives phase transitions. Defaults to the system clock.
+	Clock Clock
+	// ProvisioningDelay is how long new resources stay in their
+	// provisioning phase before becoming ready.
+	ProvisioningDelay time.Duration
+	// DeletionDelay is how long deleted resources stay in their deleting
+	// phase before they are gone.
+	DeletionDelay time.Duration
+	// ClientID and ClientSecret are the credentials accepted by the token
+	// endpoint. If empty any credentials are accepted.
+	ClientID     string
+	ClientSecret string
+	// TokenLifetime is the validity of issued access tokens. Defaults to
+	// one hour.
+	TokenLifetime time.Duration
+	// MachineImages and InstanceTypes are the catalog served by the data
+	// source routes and accepted when creating instances. Defaults to a
+	// small built-in catalog.
+	MachineImages []string
+	InstanceTypes []string
+}
+
+var (
+	defaultMachineImages = []string{"ubuntu-2204-jammy-v20230122", "ubuntu-2204-jammy-v20240308"}
+	defaultInstanceTypes = []string{"vm-spr-tny", "vm-spr-sml", "vm-spr-med"}
+)
+
+// Server is a running fake API. Use URL as both the API and the token
+// endpoint of the provider.
+type Server struct {
+	*httptest.Server
+
+	cfg Config
+	mu  sync.Mutex
+
+	tokens map[string]time.Time
+
+	// faults, behaviors and requests make up the scenario set by tests.
+	faults    []*activeFault
+	behaviors map[Kind]map[string]PhaseBehavior
+	requests  []string
+
+	instances   *collection
+	vnets       *collection
+	sshkeys     *collection
+	filesystems *collection
+	buckets     *collection
+	users       *collection
+	clusters    *collection
+	// nodeGroups, storages and vips hold the children of a cluster, keyed
+	// by cluster uuid.
+	nodeGroups map[string]*collection
+	storages   map[string]*collection
+	vips       map[string]*collection
+	nextVipID  int64
+	ipCounter  int
+}
+
+// New starts a fake server configured by cfg. Call Close when done.
+func New(cfg Config) *Server {
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	if cfg.TokenLifetime == 0 {
+		cfg.TokenLifetime = time.Hour
+	}
+	if len(cfg.MachineImages) == 0 {
+		cfg.MachineImages = defaultMachineImages
+	}
+	if len(cfg.InstanceTypes) == 0 {
+		cfg.InstanceTypes = defaultInstanceTypes
+	}
+
+	behaviors := map[Kind]map[string]PhaseBehavior{}
+	for _, kind := range []Kind{KindInstance, KindFilesystem, KindBucket, KindCluster, KindNodeGroup, KindStorage, KindLoadBalancer} {
+		behaviors[kind] = map[string]PhaseBehavior{}
+	}
+
+	s := &Server{
+		cfg:         cfg,
+		behaviors:   behaviors,
+		tokens:      map[string]time.Time{},
+		instances:   newCollection(&instanceLifecycle, behaviors[KindInstance]),
+		vnets:       newCollection(nil, nil),
+		sshkeys:     newCollection(nil, nil),
+		filesystems: newCollection(&filesystemLifecycle, behaviors[KindFilesystem]),
+		buckets:     newCollection(&bucketLifecycle, behaviors[KindBucket]),
+		users:       newCollection(nil, nil),
+		clusters:    newCollection(&clusterLifecycle, behaviors[KindCluster]),
+		nodeGroups:  map[string]*collection{},
+		storages:    map[string]*collection{},
+		vips:        map[string]*collection{},
+		nextVipID:   1,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// IssueToken returns a bearer token accepted by the server, for clients
+// configured with an api token instead of client credentials.
+func (s *Server) IssueToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issueToken()
+}
+
+// RevokeTokens invalidates every token issued so far, so the next API call
+// is answered with 401.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]time.Time{}
+}
+
+func (s *Server) issueToken() string {
+	expireAt := s.cfg.Clock.Now().Add(s.cfg.TokenLifetime)
+	header := b64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims := b64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d,"jti":%q}`, expireAt.Unix(), newID())))
+	token := header + "." + claims + "."
+	s.tokens[token] = expireAt
+	return token
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Request-Id", newID())
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault.LoseResponse {
+		s.serve(httptest.NewRecorder(), r)
+	}
+	if fault.apply(w, r) {
+		return
+	}
+	s.serve(w, r)
+}
+
+// serve answers r without faults.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/oauth2/token" {
+		s.handleToken(w, r)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if expireAt, ok := s.tokens[token]; !ok || !s.cfg.Clock.Now().Before(expireAt) {
+		writeError(w, http.StatusUnauthorized, 16, "invalid or expired token")
+		return
+	}
+
+	s.route(w, r)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || r.FormValue("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, 3, "client credentials grant with basic auth required")
+		return
+	}
+	if s.cfg.ClientID != "" && (clientID != s.cfg.ClientID || clientSecret != s.cfg.ClientSecret) {
+		writeError(w, http.StatusUnauthorized, 16, "invalid client credentials")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": s.issueToken(),
+		"token_type":   "Bearer",
+		"expires_in":   int(s.cfg.TokenLifetime.Seconds()),
+	})
+}
+
+// route dispatches API requests by path:
+//
+//	/v1/machineimages
+//	/v1/instancetypes
+//	/v1/cloudaccounts/{account}/{resource}/...
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "machineimages":
+		s.handleMachineImages(w, r)
+	case len(parts) == 2 && parts[1] == "instancetypes":
+		s.handleInstanceTypes(w, r)
+	case len(parts) >= 4 && parts[1] == "cloudaccounts":
+		account, rest := parts[2], parts[3:]
+		switch rest[0] {
+		case "instances":
+			s.handleInstances(w, r, account, rest[1:])
+		case "vnets":
+			s.handleVNets(w, r, account, rest[1:])
+		case "sshpublickeys":
+			s.handleSSHKeys(w, r, account, rest[1:])
+		case "filesystems":
+			s.handleFilesystems(w, r, account, rest[1:])
+		case "objects":
+			s.handleObjects(w, r, account, rest[1:])
+		case "iks":
+			s.handleIKS(w, r, account, rest[1:])
+		default:
+			writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+		}
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func (s *Server) handleMachineImages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+		return
+	}
+	items := []map[string]any{}
+	for _, name := range s.cfg.MachineImages {
+		items = append(items, map[string]any{
+			"metadata": map[string]any{"name": name},
+			"spec": map[string]any{
+				"description":        "Machine image " + name,
+				"instanceCategories": []string{"VirtualMachine"},
+				"instanceTypes":      s.cfg.InstanceTypes,
+			},
+			"hidden": false,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) handleInstanceTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, 12, "method not allowed")
+		return
+	}
+	items := []map[string]any{}
+	for _, name := range s.cfg.InstanceTypes {
+		items = append(items, map[string]any{
+			"metadata": map[string]any{"name": name},
+			"spec": map[string]any{
+				"description":      "Instance type " + name,
+				"instanceCategory": "VirtualMachine",
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error body in the format of the Intel Cloud API,
+// with code being the gRPC status code.
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]any{
+		"code":    code,
+		"message": message,
+		"details": []any{},
+	})
+}
+
+// decodeBody reads the JSON request body into a generic document.
+func decodeBody(r *http.Request) (map[string]any, error) {
+	doc := map[string]any{}
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid request body: %v", err)
+	}
+	return doc, nil
+}
+
+// newID returns a random UUID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,148 @@
+package itacfake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices"
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+	"terraform-provider-intelcloud/pkg/itacservices/itacfake"
+)
+
+func newClient(t *testing.T, srv *itacfake.Server) *itacservices.IDCServicesClient {
+	t.Helper()
+	host, account, region := srv.URL, "123456789012", "us-fake-1"
+	id, secret := "client-id", "client-secret"
+	client, err := itacservices.NewClient(context.Background(), &host, &host, &account, &id, &secret, &region)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return client
+}
+
+func TestInstanceLifecycle(t *testing.T) {
+	clock := itacfake.NewManualClock(time.Now())
+	srv := itacfake.New(itacfake.Config{Clock: clock, ProvisioningDelay: time.Minute})
+	defer srv.Close()
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	key := &itacservices.SSHKeyCreateRequest{}
+	key.Metadata.Name = "key"
+	key.Spec.SSHPublicKey = "ssh-ed25519 AAAA"
+	if _, err := client.CreateSSHkey(ctx, key); err != nil {
+		t.Fatalf("creating ssh key: %v", err)
+	}
+
+	in := &itacservices.InstanceCreateRequest{}
+	in.Metadata.Name = "vm"
+	in.Spec.InstanceType = "vm-spr-sml"
+	in.Spec.MachineImage = "ubuntu-2204-jammy-v20240308"
+	in.Spec.SshPublicKeyNames = []string{"key"}
+	inst, err := client.CreateInstance(ctx, in, true)
+	if err != nil {
+		t.Fatalf("creating instance: %v", err)
+	}
+	if inst.Status.Phase != "Provisioning" {
+		t.Errorf("phase = %q, want Provisioning", inst.Status.Phase)
+	}
+
+	clock.Advance(time.Minute)
+	inst, err = client.GetInstanceByResourceId(ctx, inst.Metadata.ResourceId)
+	if err != nil {
+		t.Fatalf("reading instance: %v", err)
+	}
+	if inst.Status.Phase != "Ready" {
+		t.Errorf("phase = %q, want Ready", inst.Status.Phase)
+	}
+
+	if _, err := client.CreateInstance(ctx, in, true); !errors.As(err, new(*common.ConflictError)) {
+		t.Errorf("creating duplicate instance: got %v, want conflict", err)
+	}
+
+	if err := client.DeleteInstanceByResourceId(ctx, inst.Metadata.ResourceId); err != nil {
+		t.Fatalf("deleting instance: %v", err)
+	}
+	if _, err := client.GetInstanceByResourceId(ctx, inst.Metadata.ResourceId); !common.IsNotFound(err) {
+		t.Errorf("reading deleted instance: got %v, want not found", err)
+	}
+}
+
+func TestIKSCluster(t *testing.T) {
+	srv := itacfake.New(itacfake.Config{})
+	defer srv.Close()
+	client := newClient(t, srv)
+	ctx := context.Background()
+
+	cluster, _, err := client.CreateIKSCluster(ctx, &itacservices.IKSCreateRequest{
+		Name:         "cluster",
+		K8sVersion:   "1.28",
+		InstanceType: "iks-cluster",
+		RuntimeName:  "Containerd",
+	}, false)
+	if err != nil {
+		t.Fatalf("creating cluster: %v", err)
+	}
+	if cluster.ClusterState != "Active" {
+		t.Errorf("cluster state = %q, want Active", cluster.ClusterState)
+	}
+
+	ng, _, err := client.CreateIKSNodeGroup(ctx, &itacservices.IKSNodeGroupCreateRequest{
+		Name:           "ng",
+		Count:          2,
+		InstanceTypeId: "vm-spr-sml",
+	}, cluster.ResourceId, false)
+	if err != nil {
+		t.Fatalf("creating node group: %v", err)
+	}
+	if ng.Count != 2 || ng.State != "Active" {
+		t.Errorf("node group = %+v, want 2 active nodes", ng)
+	}
+
+	if _, _, err := client.CreateIKSStorage(ctx, &itacservices.IKSStorageCreateRequest{Enable: true, Size: "30Gi"}, cluster.ResourceId); err != nil {
+		t.Fatalf("creating storage: %v", err)
+	}
+	lb, _, err := client.CreateIKSLoadBalancer(ctx, &itacservices.IKSLoadBalancerRequest{Name: "lb", Port: 80, VIPType: "public"}, cluster.ResourceId)
+	if err != nil {
+		t.Fatalf("creating load balancer: %v", err)
+	}
+
+	cluster, _, err = client.GetIKSClusterByClusterUUID(ctx, cluster.ResourceId)
+	if err != nil {
+		t.Fatalf("reading cluster: %v", err)
+	}
+	if len(cluster.NodeGroups) != 1 || len(cluster.Storages) != 1 || len(cluster.VIPs) != 1 || !cluster.StorageEnabled {
+		t.Errorf("cluster children = %+v", cluster)
+	}
+	if cluster.VIPs[0].Id != lb.ID {
+		t.Errorf("vip id = %d, want %d", cluster.VIPs[0].Id, lb.ID)
+	}
+
+	if err := client.UpgradeCluster(ctx, &itacservices.UpgradeClusterRequest{ClusterId: cluster.ResourceId, K8sVersion: "1.29"}); err != nil {
+		t.Fatalf("upgrading cluster: %v", err)
+	}
+	kubeconfig, err := client.GetClusterKubeconfig(ctx, cluster.ResourceId)
+	if err != nil || *kubeconfig == "" {
+		t.Fatalf("reading kubeconfig: %v", err)
+	}
+
+	if err := client.DeleteIKSCluster(ctx, cluster.ResourceId); err != nil {
+		t.Fatalf("deleting cluster: %v", err)
+	}
+	if _, _, err := client.GetIKSNodeGroupByID(ctx, cluster.ResourceId, ng.ID); !common.IsNotFound(err) {
+		t.Errorf("reading node group of deleted cluster: got %v, want not found", err)
+	}
+}
+
+func TestTokenRenewal(t *testing.T) {
+	srv := itacfake.New(itacfake.Config{ClientID: "client-id", ClientSecret: "client-secret"})
+	defer srv.Close()
+	client := newClient(t, srv)
+
+	srv.RevokeTokens()
+	if _, err := client.GetSSHKeys(context.Background()); err != nil {
+		t.Fatalf("listing ssh keys after token revocation: %v", err)
+	}
+}
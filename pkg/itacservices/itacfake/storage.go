@@ -0,0 +1,204 @@
+package itacfake
+
+import (
+	"net/http"
+	"time"
+)
+
+// handleFilesystems serves
+//
+//	/filesystems
+//	/filesystems/id/{id}
+//	/filesystems/id/{id}/user
+//	/filesystems/name/{name}
+func (s *Server) handleFilesystems(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"items": s.filesystems.list(s.now())})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		s.createFilesystem(w, r, account)
+	case len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.filesystems, rest[1], "filesystem")
+	case len(rest) == 3 && rest[0] == "id" && rest[2] == "user" && r.Method == http.MethodGet:
+		obj, ok := s.filesystems.get(rest[1], s.now())
+		if !ok {
+			writeError(w, http.StatusNotFound, 5, "filesystem "+rest[1]+" not found")
+			return
+		}
+		mount := child(child(obj.doc, "status"), "mount")
+		writeJSON(w, http.StatusOK, map[string]any{
+			"user":     mount["username"],
+			"password": mount["password"],
+		})
+	case len(rest) == 2 && rest[0] == "name" && r.Method == http.MethodPut:
+		id, ok := s.filesystems.findByField(s.now(), rest[1], "metadata", "name")
+		if !ok {
+			writeError(w, http.StatusNotFound, 5, "filesystem "+rest[1]+" not found")
+			return
+		}
+		doc, err := decodeBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 3, err.Error())
+			return
+		}
+		size := stringAt(doc, "spec", "request", "storage")
+		if size == "" {
+			writeError(w, http.StatusBadRequest, 3, "spec.request.storage is required")
+			return
+		}
+		obj, _ := s.filesystems.get(id, s.now())
+		child(child(obj.doc, "spec"), "request")["storage"] = size
+		writeJSON(w, http.StatusOK, map[string]any{})
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func (s *Server) createFilesystem(w http.ResponseWriter, r *http.Request, account string) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	name := stringAt(doc, "metadata", "name")
+	if name == "" || stringAt(doc, "spec", "request", "storage") == "" {
+		writeError(w, http.StatusBadRequest, 3, "metadata.name and spec.request.storage are required")
+		return
+	}
+	if _, exists := s.filesystems.findByField(s.now(), name, "metadata", "name"); exists {
+		writeError(w, http.StatusConflict, 6, "filesystem "+name+" already exists")
+		return
+	}
+
+	id := newID()
+	doc["metadata"] = map[string]any{
+		"resourceId":        id,
+		"cloudAccountId":    account,
+		"name":              name,
+		"description":       stringAt(doc, "metadata", "description"),
+		"creationTimestamp": s.now().UTC().Format(time.RFC3339),
+	}
+	doc["status"] = map[string]any{
+		"mount": map[string]any{
+			"clusterAddr":    "https://storage.fake.intelcloud",
+			"clusterVersion": "1.0",
+			"namespace":      account,
+			"username":       "u" + account,
+			"password":       newID(),
+			"filesystemName": name,
+		},
+	}
+	obj := s.filesystems.add(id, doc, s.now(), s.cfg.ProvisioningDelay)
+	writeJSON(w, http.StatusOK, obj.doc)
+}
+
+// handleObjects serves
+//
+//	/objects/buckets
+//	/objects/buckets/id/{id}
+//	/objects/users
+//	/objects/users/id/{id}
+func (s *Server) handleObjects(w http.ResponseWriter, r *http.Request, account string, rest []string) {
+	if len(rest) == 0 {
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+		return
+	}
+	kind, rest := rest[0], rest[1:]
+	switch {
+	case kind == "buckets" && len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"items": s.buckets.list(s.now())})
+	case kind == "buckets" && len(rest) == 0 && r.Method == http.MethodPost:
+		s.createBucket(w, r, account)
+	case kind == "buckets" && len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.buckets, rest[1], "bucket")
+	case kind == "users" && len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"users": s.users.list(s.now())})
+	case kind == "users" && len(rest) == 0 && r.Method == http.MethodPost:
+		s.createObjectUser(w, r, account)
+	case kind == "users" && len(rest) == 2 && rest[0] == "id":
+		s.handleByID(w, r, s.users, rest[1], "object user")
+	default:
+		writeError(w, http.StatusNotFound, 5, "unknown route "+r.URL.Path)
+	}
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request, account string) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	name := stringAt(doc, "metadata", "name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, 3, "metadata.name is required")
+		return
+	}
+	if _, exists := s.buckets.findByField(s.now(), name, "metadata", "name"); exists {
+		writeError(w, http.StatusConflict, 6, "bucket "+name+" already exists")
+		return
+	}
+
+	id := newID()
+	doc["metadata"] = map[string]any{
+		"resourceId":     id,
+		"cloudAccountId": account,
+		"name":           name,
+	}
+	child(child(doc, "spec"), "request")["size"] = "0"
+	doc["status"] = map[string]any{
+		"cluster": map[string]any{
+			"accessEndpoint": "https://objects.fake.intelcloud",
+			"clusterId":      "fake-cluster",
+		},
+		"securityGroup": map[string]any{
+			"networkFilterAllow": []any{},
+		},
+	}
+	obj := s.buckets.add(id, doc, s.now(), s.cfg.ProvisioningDelay)
+	writeJSON(w, http.StatusOK, obj.doc)
+}
+
+func (s *Server) createObjectUser(w http.ResponseWriter, r *http.Request, account string) {
+	doc, err := decodeBody(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 3, err.Error())
+		return
+	}
+	name := stringAt(doc, "metadata", "name")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, 3, "metadata.name is required")
+		return
+	}
+	if _, exists := s.users.findByField(s.now(), name, "metadata", "name"); exists {
+		writeError(w, http.StatusConflict, 6, "object user "+name+" already exists")
+		return
+	}
+	policies, _ := doc["spec"].([]any)
+	for _, p := range policies {
+		bucketID := stringAt(p.(map[string]any), "bucketId")
+		if _, ok := s.buckets.get(bucketID, s.now()); !ok {
+			if _, ok := s.buckets.findByField(s.now(), bucketID, "metadata", "name"); !ok {
+				writeError(w, http.StatusBadRequest, 3, "unknown bucket "+bucketID)
+				return
+			}
+		}
+	}
+
+	id := newID()
+	doc["metadata"] = map[string]any{
+		"name":           name,
+		"userId":         id,
+		"cloudAccountId": account,
+	}
+	doc["status"] = map[string]any{
+		"phase": "ObjectUserReady",
+		"principal": map[string]any{
+			"credentials": map[string]any{
+				"accessKey": newID(),
+				"secretKey": newID(),
+			},
+		},
+	}
+	obj := s.users.add(id, doc, s.now(), 0)
+	writeJSON(w, http.StatusOK, obj.doc)
+}
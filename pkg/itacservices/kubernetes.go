@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
-	"time"
 
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 
@@ -50,6 +48,13 @@ type IKSCluster struct {
 	StorageEnabled        bool           `json:"storageenabled"`
 	Storages              []K8sStorage   `json:"storages"`
 	VIPs                  []IKSVIP       `json:"vips"`
+	ClusterStatus         StatusMessage  `json:"clusterstatus"`
+}
+
+// StatusMessage is the status detail of an iks resource, whose message
+// explains a failed state.
+type StatusMessage struct {
+	Message string `json:"message"`
 }
 
 type IKSVIP struct {
@@ -70,15 +75,23 @@ type ClusterNetwork struct {
 }
 
 type NodeGroup struct {
-	ID                   string `json:"nodegroupuuid"`
-	Name                 string `json:"name"`
-	Count                int64  `json:"count"`
-	InstanceType         string `json:"instancetypeid"`
-	State                string `json:"nodegroupstate"`
-	SSHKeyNames          []SKey `json:"sshkeyname"`
-	NetworkInterfaceName string `json:"networkinterfacename"`
-	IMIID                string `json:"imiid"`
-	UserDataURL          string `json:"userdataurl"`
+	ID                   string        `json:"nodegroupuuid"`
+	Name                 string        `json:"name"`
+	Count                int64         `json:"count"`
+	InstanceType         string        `json:"instancetypeid"`
+	State                string        `json:"nodegroupstate"`
+	SSHKeyNames          []SKey        `json:"sshkeyname"`
+	NetworkInterfaceName string        `json:"networkinterfacename"`
+	IMIID                string        `json:"imiid"`
+	UserDataURL          string        `json:"userdataurl"`
+	VNets                []IKSVNet     `json:"vnets"`
+	NodeGroupStatus      StatusMessage `json:"nodegroupstatus"`
+}
+
+// IKSVNet is a network interface of the nodes of a node group.
+type IKSVNet struct {
+	AvailabilityZone string `json:"availabilityzonename"`
+	VNet             string `json:"networkinterfacevnetname"`
 }
 
 type SKey struct {
@@ -92,16 +105,13 @@ type K8sStorage struct {
 }
 
 type IKSNodeGroupCreateRequest struct {
-	Count          int64  `json:"count"`
-	Name           string `json:"name"`
-	ProductType    string `json:"instanceType"`
-	InstanceTypeId string `json:"instancetypeid"`
-	SSHKeyNames    []SKey `json:"sshkeyname"`
-	UserDataURL    string `json:"userdataurl"`
-	Interfaces     []struct {
-		AvailabilityZone string `json:"availabilityzonename"`
-		VNet             string `json:"networkinterfacevnetname"`
-	} `json:"vnets"`
+	Count          int64     `json:"count"`
+	Name           string    `json:"name"`
+	ProductType    string    `json:"instanceType"`
+	InstanceTypeId string    `json:"instancetypeid"`
+	SSHKeyNames    []SKey    `json:"sshkeyname"`
+	UserDataURL    string    `json:"userdataurl"`
+	Interfaces     []IKSVNet `json:"vnets"`
 }
 
 type IKSCreateRequest struct {
@@ -165,14 +175,10 @@ func (client *IDCServicesClient) GetKubernetesClusters(ctx context.Context) (*IK
 		return nil, nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
-	tflog.Debug(ctx, "iks read api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	tflog.Debug(ctx, "iks read api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading iks clusters")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks clusters: %w", err)
 	}
 
 	clusters := IKSClusters{}
@@ -204,17 +210,13 @@ func (client *IDCServicesClient) CreateIKSCluster(ctx context.Context, in *IKSCr
 		return nil, nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "iks create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	tflog.Debug(ctx, "iks create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading iks create response")
-	}
-	tflog.Debug(ctx, "iks create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks create response: %w", err)
 	}
+	tflog.Debug(ctx, "iks create api response", map[string]any{"retcode": retcode})
 
 	cluster := &IKSCluster{}
 	if err := json.Unmarshal(retval, cluster); err != nil {
@@ -227,29 +229,42 @@ func (client *IDCServicesClient) CreateIKSCluster(ctx context.Context, in *IKSCr
 			return cluster, nil, fmt.Errorf("error reading iks cluster state")
 		}
 	} else {
-		backoffTimer := retry.NewConstant(5 * time.Second)
-		backoffTimer = retry.WithMaxDuration(1800*time.Second, backoffTimer)
-
-		if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
-			cluster, _, err = client.GetIKSClusterByClusterUUID(ctx, cluster.ResourceId)
-			if err != nil {
-				return fmt.Errorf("error reading instance state")
-			}
-			if cluster.ClusterState == "Active" {
-				return nil
-			} else if cluster.ClusterState == "Failed" {
-				return fmt.Errorf("instance state failed")
-			} else {
-				return retry.RetryableError(fmt.Errorf("iks cluster state not ready, retry again"))
-			}
-		}); err != nil {
-			return nil, nil, fmt.Errorf("iks cluster state not ready after maximum retries")
+		cluster, err = client.WaitForIKSCluster(ctx, cluster.ResourceId)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
 	return cluster, client.Cloudaccount, nil
 }
 
+// WaitForIKSCluster polls the cluster until it is active. Errors carry the
+// uuid of the cluster as a common.ProvisioningError.
+func (client *IDCServicesClient) WaitForIKSCluster(ctx context.Context, clusterUUID string) (*IKSCluster, error) {
+	var cluster *IKSCluster
+	var state, message string
+	backoffTimer := waitBackoff(ctx, DefaultIKSClusterTimeout)
+
+	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
+		var err error
+		cluster, _, err = client.GetIKSClusterByClusterUUID(ctx, clusterUUID)
+		if err != nil {
+			return fmt.Errorf("error reading iks cluster state: %w", err)
+		}
+		state, message = cluster.ClusterState, cluster.ClusterStatus.Message
+		if state == "Active" {
+			return nil
+		} else if state == "Failed" {
+			return &common.ProvisioningError{Kind: "iks cluster", ResourceId: clusterUUID, Phase: state, Message: message}
+		} else {
+			return retry.RetryableError(fmt.Errorf("iks cluster state not ready, retry again"))
+		}
+	}); err != nil {
+		return nil, provisioningError("iks cluster", clusterUUID, state, message, err)
+	}
+	return cluster, nil
+}
+
 func (client *IDCServicesClient) GetIKSClusterByClusterUUID(ctx context.Context, clusterUUID string) (*IKSCluster, *string, error) {
 	params := struct {
 		Host         string
@@ -267,15 +282,11 @@ func (client *IDCServicesClient) GetIKSClusterByClusterUUID(ctx context.Context,
 		return nil, nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading sshkey by resource id")
-	}
-	tflog.Debug(ctx, "iks get cluster by UUID api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks cluster by uuid: %w", err)
 	}
+	tflog.Debug(ctx, "iks get cluster by UUID api response", map[string]any{"retcode": retcode})
 
 	cluster := IKSCluster{}
 	if err := json.Unmarshal(retval, &cluster); err != nil {
@@ -302,18 +313,20 @@ func (client *IDCServicesClient) DeleteIKSCluster(ctx context.Context, clusterUU
 	}
 
 	tflog.Debug(ctx, "iks cluster delete api", map[string]any{"parsedurl": parsedURL})
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting sshkey by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+		return fmt.Errorf("error deleting iks cluster by uuid: %w", err)
 	}
 
 	tflog.Debug(ctx, "iks cluster delete api", map[string]any{"retcode": retcode})
 
-	return nil
+	return waitForDeletion(ctx, "iks cluster", DefaultIKSClusterTimeout, func(ctx context.Context) (string, error) {
+		cluster, _, err := client.GetIKSClusterByClusterUUID(ctx, clusterUUID)
+		if err != nil {
+			return "", err
+		}
+		return cluster.ClusterState, nil
+	}, "Deleted")
 }
 
 func (client *IDCServicesClient) CreateIKSNodeGroup(ctx context.Context, in *IKSNodeGroupCreateRequest, clusterUUID string, async bool) (*NodeGroup, *string, error) {
@@ -338,40 +351,38 @@ func (client *IDCServicesClient) CreateIKSNodeGroup(ctx context.Context, in *IKS
 		return nil, nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "iks node group create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	tflog.Debug(ctx, "iks node group create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading iks node group create response")
-	}
-	tflog.Debug(ctx, "iks node group create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks node group create response: %w", err)
 	}
+	tflog.Debug(ctx, "iks node group create api response", map[string]any{"retcode": retcode})
 
 	ng := &NodeGroup{}
 	if err := json.Unmarshal(retval, ng); err != nil {
 		return nil, nil, fmt.Errorf("error parsing node group response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(3000*time.Second, backoffTimer)
+	ngId := ng.ID
+	var state, message string
+	backoffTimer := waitBackoff(ctx, DefaultIKSNodeGroupTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
-		ng, _, err = client.GetIKSNodeGroupByID(ctx, clusterUUID, ng.ID)
+		ng, _, err = client.GetIKSNodeGroupByID(ctx, clusterUUID, ngId)
 		if err != nil {
-			return fmt.Errorf("error reading node group state")
+			return fmt.Errorf("error reading node group state: %w", err)
 		}
 		tflog.Debug(ctx, "iks node group create api response", map[string]any{"nodegroupuuid": ng.ID, "state": ng.State})
-		if ng.State == "Active" {
+		state, message = ng.State, ng.NodeGroupStatus.Message
+		if state == "Active" {
 			return nil
-		} else if ng.State == "Failed" {
-			return fmt.Errorf("node group state failed")
+		} else if state == "Failed" {
+			return &common.ProvisioningError{Kind: "iks node group", ResourceId: ngId, Phase: state, Message: message}
 		}
 		return retry.RetryableError(fmt.Errorf("iks node group state not ready, retry again"))
 	}); err != nil {
-		return nil, nil, fmt.Errorf("iks node group state not ready after maximum retries")
+		return nil, nil, provisioningError("iks node group", ngId, state, message, err)
 	}
 	return ng, client.Cloudaccount, nil
 }
@@ -395,15 +406,11 @@ func (client *IDCServicesClient) GetIKSNodeGroupByID(ctx context.Context, cluste
 		return nil, nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading node group resource by id")
-	}
-	tflog.Debug(ctx, "iks node group read response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading node group resource by id: %w", err)
 	}
+	tflog.Debug(ctx, "iks node group read response", map[string]any{"retcode": retcode})
 
 	nodeGroup := NodeGroup{}
 	if err := json.Unmarshal(retval, &nodeGroup); err != nil {
@@ -434,47 +441,45 @@ func (client *IDCServicesClient) CreateIKSStorage(ctx context.Context, in *IKSSt
 		return nil, nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "iks file storage create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	tflog.Debug(ctx, "iks file storage create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading iks file storage create response")
-	}
-	tflog.Debug(ctx, "iks file storage create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks file storage create response: %w", err)
 	}
+	tflog.Debug(ctx, "iks file storage create api response", map[string]any{"retcode": retcode})
 
 	storage := &K8sStorage{}
 	if err := json.Unmarshal(retval, storage); err != nil {
 		return nil, nil, fmt.Errorf("error parsing node group response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(3000*time.Second, backoffTimer)
+	// The storage has no id nor status message of its own, errors carry the
+	// uuid and status message of the cluster.
+	var state, message string
+	backoffTimer := waitBackoff(ctx, DefaultIKSStorageTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
 		iksCluster, _, err := client.GetIKSClusterByClusterUUID(ctx, clusterUUID)
 		if err != nil {
-			return fmt.Errorf("error reading node group state")
+			return fmt.Errorf("error reading iks file storage state: %w", err)
 		}
+		message = iksCluster.ClusterStatus.Message
 		for _, v := range iksCluster.Storages {
 			if strings.EqualFold(v.Size, storage.Size) {
+				state = v.State
 				if v.State == "Active" {
 					storage.Provider = v.Provider
 					storage.State = v.State
 					return nil
 				} else if v.State == "Failed" {
-					return fmt.Errorf("file storage state failed")
+					return &common.ProvisioningError{Kind: "iks file storage", ResourceId: clusterUUID, Phase: state, Message: message}
 				}
-			} else {
-				return retry.RetryableError(fmt.Errorf("iks file storage state not ready, retry again"))
 			}
 		}
 		return retry.RetryableError(fmt.Errorf("iks file storage state not ready, retry again"))
 	}); err != nil {
-		return nil, nil, fmt.Errorf("iks node group state not ready after maximum retries")
+		return nil, nil, provisioningError("iks file storage", clusterUUID, state, message, err)
 	}
 
 	return storage, client.Cloudaccount, nil
@@ -502,25 +507,20 @@ func (client *IDCServicesClient) CreateIKSLoadBalancer(ctx context.Context, in *
 		return nil, nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "iks load balancer create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	tflog.Debug(ctx, "iks load balancer create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("error reading iks load balancer create response")
-	}
-	tflog.Debug(ctx, "iks load balancer create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, nil, common.MapHttpError(retcode, retval)
+		return nil, nil, fmt.Errorf("error reading iks load balancer create response: %w", err)
 	}
+	tflog.Debug(ctx, "iks load balancer create api response", map[string]any{"retcode": retcode})
 
 	iksLB := &IKSLoadBalancer{}
 	if err := json.Unmarshal(retval, iksLB); err != nil {
 		return nil, nil, fmt.Errorf("error parsing load balancer response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(3000*time.Second, backoffTimer)
+	backoffTimer := waitBackoff(ctx, DefaultIKSLoadBalancerTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
 		iksLB, err = client.GetIKSLoadBalancerByID(ctx, clusterUUID, iksLB.ID)
@@ -558,15 +558,11 @@ func (client *IDCServicesClient) GetIKSLoadBalancerByID(ctx context.Context, clu
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading load balancer resource by id")
-	}
-	tflog.Debug(ctx, "iks load balancer read response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading load balancer resource by id: %w", err)
 	}
+	tflog.Debug(ctx, "iks load balancer read response", map[string]any{"retcode": retcode})
 
 	iksLB := IKSLoadBalancer{}
 	if err := json.Unmarshal(retval, &iksLB); err != nil {
@@ -592,15 +588,11 @@ func (client *IDCServicesClient) GetIKSLoadBalancerByClusterUUID(ctx context.Con
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading load balancer resource by cluster")
-	}
-	tflog.Debug(ctx, "iks load balancer read response", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading load balancer resource by cluster: %w", err)
 	}
+	tflog.Debug(ctx, "iks load balancer read response", map[string]any{"retcode": retcode})
 
 	resp := IKSLBsByCluster{}
 	if err := json.Unmarshal(retval, &resp); err != nil {
@@ -629,15 +621,47 @@ func (client *IDCServicesClient) DeleteIKSNodeGroup(ctx context.Context, cluster
 	}
 
 	tflog.Debug(ctx, "iks node group delete api", map[string]any{"parsedurl": parsedURL})
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting iks node group by resource id")
+		return fmt.Errorf("error deleting iks node group by resource id: %w", err)
 	}
 	tflog.Debug(ctx, "iks node group delete api", map[string]any{"retcode": retcode})
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+
+	return waitForDeletion(ctx, "iks node group", DefaultIKSNodeGroupTimeout, func(ctx context.Context) (string, error) {
+		ng, _, err := client.GetIKSNodeGroupByID(ctx, clusterId, ngId)
+		if err != nil {
+			return "", err
+		}
+		return ng.State, nil
+	}, "Deleted")
+}
+
+func (client *IDCServicesClient) DeleteIKSLoadBalancer(ctx context.Context, clusterUUID string, vipId int64) error {
+	params := struct {
+		Host         string
+		Cloudaccount string
+		ClusterUUID  string
+		VipID        int64
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+		ClusterUUID:  clusterUUID,
+		VipID:        vipId,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(getIKSLBURLByID, params)
+	if err != nil {
+		return fmt.Errorf("error parsing the url")
 	}
 
+	tflog.Debug(ctx, "iks load balancer delete api", map[string]any{"parsedurl": parsedURL})
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting iks load balancer by id: %w", err)
+	}
+	tflog.Debug(ctx, "iks load balancer delete api", map[string]any{"retcode": retcode})
+
 	return nil
 }
 
@@ -658,14 +682,11 @@ func (client *IDCServicesClient) GetClusterKubeconfig(ctx context.Context, clust
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error calling get kubeconfig api")
+		return nil, fmt.Errorf("error calling get kubeconfig api: %w", err)
 	}
 	tflog.Debug(ctx, "iks get kubeconfig", map[string]any{"retcode": retcode})
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
-	}
 
 	resp := KubeconfigResponse{}
 	if err := json.Unmarshal(retval, &resp); err != nil {
@@ -700,37 +721,35 @@ func (client *IDCServicesClient) UpgradeCluster(ctx context.Context, in *Upgrade
 		return fmt.Errorf("error parsing input arguments")
 	}
 
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
 	if err != nil {
-		return fmt.Errorf("error calling upgrade cluster api")
-	}
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+		return fmt.Errorf("error calling upgrade cluster api: %w", err)
 	}
-	tflog.Debug(ctx, "iks upgrade cluster", map[string]any{"retcode": retcode, "retval": retval})
+	tflog.Debug(ctx, "iks upgrade cluster", map[string]any{"retcode": retcode})
 
 	cluster := &IKSCluster{}
 	if err := json.Unmarshal(retval, cluster); err != nil {
 		return fmt.Errorf("error parsing instance response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(1800*time.Second, backoffTimer)
+	var state, message string
+	backoffTimer := waitBackoff(ctx, DefaultIKSClusterTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
 		cluster, _, err = client.GetIKSClusterByClusterUUID(ctx, in.ClusterId)
 		if err != nil {
-			return fmt.Errorf("error reading instance state after upgrade")
+			return fmt.Errorf("error reading iks cluster state after upgrade: %w", err)
 		}
-		if cluster.ClusterState == "Active" {
+		state, message = cluster.ClusterState, cluster.ClusterStatus.Message
+		if state == "Active" {
 			return nil
-		} else if cluster.ClusterState == "Failed" {
-			return fmt.Errorf("instance state failed")
+		} else if state == "Failed" {
+			return &common.ProvisioningError{Kind: "iks cluster", ResourceId: in.ClusterId, Phase: state, Message: message}
 		} else {
 			return retry.RetryableError(fmt.Errorf("iks cluster state not ready, retry again"))
 		}
 	}); err != nil {
-		return fmt.Errorf("iks cluster state not ready after maximum retries")
+		return provisioningError("iks cluster", in.ClusterId, state, message, err)
 	}
 
 	return nil
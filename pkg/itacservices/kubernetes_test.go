@@ -0,0 +1,322 @@
+package itacservices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+)
+
+const clusterPrefix = "/v1/cloudaccounts/" + testCloudaccount + "/iks/clusters/cl-1"
+
+func TestCreateIKSStorage(t *testing.T) {
+	const storageBody = `{"enablestorage": true, "storagesize": "5TB"}`
+
+	tests := []struct {
+		name         string
+		steps        []apiStep
+		timeout      time.Duration
+		wantProvider string
+		wantErr      func(error) bool
+	}{
+		{
+			name: "active after update",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusOK, response: `{"size": "5TB"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "storages": []}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "storages": [{"size": "5TB", "state": "Updating"}]}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "storages": [{"size": "5TB", "state": "Active", "storageprovider": "weka"}]}`},
+			},
+			wantProvider: "weka",
+		},
+		{
+			name: "matched among other storages",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusOK, response: `{"size": "5TB"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK,
+					response: `{"uuid": "cl-1", "storages": [{"size": "1TB", "state": "Active", "storageprovider": "other"}, {"size": "5tb", "state": "Active", "storageprovider": "weka"}]}`},
+			},
+			wantProvider: "weka",
+		},
+		{
+			name: "failed",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusOK, response: `{"size": "5TB"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK,
+					response: `{"uuid": "cl-1", "storages": [{"size": "5TB", "state": "Failed"}], "clusterstatus": {"message": "storage quota exceeded"}}`},
+			},
+			wantErr: func(err error) bool {
+				var provErr *common.ProvisioningError
+				return errors.As(err, &provErr) && provErr.Err == nil && provErr.ResourceId == "cl-1" &&
+					provErr.Phase == "Failed" && provErr.Message == "storage quota exceeded"
+			},
+		},
+		{
+			name: "never active",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusOK, response: `{"size": "5TB"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "storages": [{"size": "5TB", "state": "Updating"}]}`},
+			},
+			timeout: 50 * time.Millisecond,
+			wantErr: func(err error) bool {
+				var provErr *common.ProvisioningError
+				return isDeadlineExceeded(err) && errors.As(err, &provErr) && provErr.ResourceId == "cl-1" && provErr.Phase == "Updating"
+			},
+		},
+		{
+			name: "unknown cluster",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusNotFound, response: errorBody(5, "not found")},
+			},
+			wantErr: common.IsNotFound,
+		},
+		{
+			name: "invalid size",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/storage", body: storageBody, status: http.StatusBadRequest, response: errorBody(3, "invalid size")},
+			},
+			wantErr: isError[*common.ValidationError],
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-region-1", tc.steps)
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			storage, _, err := client.CreateIKSStorage(ctx, &IKSStorageCreateRequest{Enable: true, Size: "5TB"}, "cl-1")
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script.done()
+			if storage.State != "Active" || storage.Provider != tc.wantProvider {
+				t.Errorf("storage = %+v, want Active from %s", storage, tc.wantProvider)
+			}
+		})
+	}
+}
+
+func TestUpgradeCluster(t *testing.T) {
+	const upgradeBody = `{"k8sversionname": "1.28"}`
+
+	tests := []struct {
+		name    string
+		steps   []apiStep
+		timeout time.Duration
+		wantErr func(error) bool
+	}{
+		{
+			name: "active after upgrade",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/upgrade", body: upgradeBody, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Updating"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Updating"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Active", "k8sversion": "1.28"}`},
+			},
+		},
+		{
+			name: "failed",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/upgrade", body: upgradeBody, status: http.StatusOK, response: `{"uuid": "cl-1"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Failed"}`},
+			},
+			wantErr: anyError,
+		},
+		{
+			name: "never active",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/upgrade", body: upgradeBody, status: http.StatusOK, response: `{"uuid": "cl-1"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Updating"}`},
+			},
+			timeout: 50 * time.Millisecond,
+			wantErr: isDeadlineExceeded,
+		},
+		{
+			name: "unsupported version",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/upgrade", body: upgradeBody, status: http.StatusBadRequest, response: errorBody(3, "unsupported version")},
+			},
+			wantErr: isError[*common.ValidationError],
+		},
+		{
+			name: "malformed response",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/upgrade", body: upgradeBody, status: http.StatusOK, response: `{"uuid": `},
+			},
+			wantErr: anyError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-region-1", tc.steps)
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err := client.UpgradeCluster(ctx, &UpgradeClusterRequest{ClusterId: "cl-1", K8sVersion: "1.28"})
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script.done()
+		})
+	}
+}
+
+func TestIKSLoadBalancer(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []apiStep
+		call    func(ctx context.Context, client *IDCServicesClient) error
+		wantErr func(error) bool
+	}{
+		{
+			name: "create",
+			steps: []apiStep{
+				{method: http.MethodPost, path: clusterPrefix + "/vips", body: `{"name": "web", "port": 80, "viptype": "public"}`, status: http.StatusOK, response: `{"vipid": 7}`},
+				{method: http.MethodGet, path: clusterPrefix + "/vips/7", status: http.StatusOK, response: `{"vipid": 7, "vipstate": "Pending"}`},
+				{method: http.MethodGet, path: clusterPrefix + "/vips/7", status: http.StatusOK, response: `{"vipid": 7, "vipstate": "Active", "vipip": "10.0.0.7"}`},
+			},
+			call: func(ctx context.Context, client *IDCServicesClient) error {
+				lb, _, err := client.CreateIKSLoadBalancer(ctx, &IKSLoadBalancerRequest{Name: "web", Port: 80, VIPType: "public"}, "cl-1")
+				if err == nil && lb.VIPIP != "10.0.0.7" {
+					t.Errorf("vip ip = %q, want 10.0.0.7", lb.VIPIP)
+				}
+				return err
+			},
+		},
+		{
+			name: "delete",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix + "/vips/7", status: http.StatusOK, response: `{}`},
+			},
+			call: func(ctx context.Context, client *IDCServicesClient) error {
+				return client.DeleteIKSLoadBalancer(ctx, "cl-1", 7)
+			},
+		},
+		{
+			name: "delete unknown",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix + "/vips/7", status: http.StatusNotFound, response: errorBody(5, "not found")},
+			},
+			call: func(ctx context.Context, client *IDCServicesClient) error {
+				return client.DeleteIKSLoadBalancer(ctx, "cl-1", 7)
+			},
+			wantErr: common.IsNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-region-1", tc.steps)
+
+			err := tc.call(context.Background(), client)
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script.done()
+		})
+	}
+}
+
+func TestDeleteIKSCluster(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []apiStep
+		timeout time.Duration
+		wantErr func(error) bool
+	}{
+		{
+			name: "gone after deleting",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix, status: http.StatusOK, response: `{}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Deleting"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusNotFound, response: errorBody(5, "not found")},
+			},
+		},
+		{
+			name: "deleted phase",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix, status: http.StatusOK, response: `{}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Deleting"}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Deleted"}`},
+			},
+		},
+		{
+			name: "never deleted",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix, status: http.StatusOK, response: `{}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusOK, response: `{"uuid": "cl-1", "clusterstate": "Deleting"}`},
+			},
+			timeout: 50 * time.Millisecond,
+			wantErr: isDeadlineExceeded,
+		},
+		{
+			name: "read fails",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix, status: http.StatusOK, response: `{}`},
+				{method: http.MethodGet, path: clusterPrefix, status: http.StatusForbidden, response: errorBody(7, "forbidden")},
+			},
+			wantErr: isError[*common.ForbiddenError],
+		},
+		{
+			name: "unknown cluster",
+			steps: []apiStep{
+				{method: http.MethodDelete, path: clusterPrefix, status: http.StatusNotFound, response: errorBody(5, "not found")},
+			},
+			wantErr: common.IsNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-region-1", tc.steps)
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err := client.DeleteIKSCluster(ctx, "cl-1")
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script.done()
+		})
+	}
+}
@@ -4,23 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"terraform-provider-intelcloud/pkg/itacservices/common"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	retry "github.com/sethvargo/go-retry"
 )
 
 const (
+	getAllObjectStorageBucketsURL         = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/buckets"
 	createObjectStorageBucketURL          = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/buckets"
 	getObjectStorageBucketByResourceId    = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/buckets/id/{{.ResourceId}}"
 	deleteObjectStorageBucketByResourceId = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/buckets/id/{{.ResourceId}}"
 	createObjectStorageUserURL            = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/users"
 	deleteObjectStorageUserURL            = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/users/id/{{.ResourceId}}"
 	getObjectStorageUserURL               = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/users/id/{{.ResourceId}}"
+	getAllObjectStorageUsersURL           = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/objects/users"
 )
 
+type ObjectBuckets struct {
+	Buckets []ObjectBucket `json:"items"`
+}
+
+type ObjectUsers struct {
+	Users []ObjectUser `json:"users"`
+}
+
 type ObjectBucketCreateRequest struct {
 	Metadata struct {
 		Name string `json:"name"`
@@ -92,6 +100,34 @@ type ObjectUser struct {
 	}
 }
 
+func (client *IDCServicesClient) GetObjectBuckets(ctx context.Context) (*ObjectBuckets, error) {
+	params := struct {
+		Host         string
+		Cloudaccount string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(getAllObjectStorageBucketsURL, params)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the url")
+	}
+
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object buckets: %w", err)
+	}
+	tflog.Debug(ctx, "object buckets read api", map[string]any{"retcode": retcode})
+
+	buckets := ObjectBuckets{}
+	if err := json.Unmarshal(retval, &buckets); err != nil {
+		return nil, fmt.Errorf("error parsing object buckets response")
+	}
+	return &buckets, nil
+}
+
 func (client *IDCServicesClient) CreateObjectStorageBucket(ctx context.Context, in *ObjectBucketCreateRequest) (*ObjectBucket, error) {
 	params := struct {
 		Host         string
@@ -112,14 +148,11 @@ func (client *IDCServicesClient) CreateObjectStorageBucket(ctx context.Context,
 		return nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "bucket create api", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
-	tflog.Debug(ctx, "bucket create api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	tflog.Debug(ctx, "bucket create api", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
+	tflog.Debug(ctx, "bucket create api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading bucket create response")
-	}
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading bucket create response: %w", err)
 	}
 
 	bucket := &ObjectBucket{}
@@ -127,8 +160,7 @@ func (client *IDCServicesClient) CreateObjectStorageBucket(ctx context.Context,
 		return nil, fmt.Errorf("error parsing bucket response")
 	}
 
-	backoffTimer := retry.NewConstant(5 * time.Second)
-	backoffTimer = retry.WithMaxDuration(300*time.Second, backoffTimer)
+	backoffTimer := waitBackoff(ctx, DefaultBucketTimeout)
 
 	if err := retry.Do(ctx, backoffTimer, func(_ context.Context) error {
 		bucket, err = client.GetObjectBucketByResourceId(ctx, bucket.Metadata.ResourceId)
@@ -166,13 +198,9 @@ func (client *IDCServicesClient) GetObjectBucketByResourceId(ctx context.Context
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading bucket by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading bucket by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "object read api", map[string]any{"retcode": retcode})
@@ -200,20 +228,20 @@ func (client *IDCServicesClient) DeleteBucketByResourceId(ctx context.Context, r
 		return fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting object bucket by resource id")
-	}
-
-	tflog.Debug(ctx, "object bucket delete api", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+		return fmt.Errorf("error deleting object bucket by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "object bucket delete api", map[string]any{"retcode": retcode})
 
-	return nil
+	return waitForDeletion(ctx, "object bucket", DefaultBucketTimeout, func(ctx context.Context) (string, error) {
+		bucket, err := client.GetObjectBucketByResourceId(ctx, resourceId)
+		if err != nil {
+			return "", err
+		}
+		return bucket.Status.Phase, nil
+	}, "BucketDeleted")
 }
 
 func (client *IDCServicesClient) CreateObjectStorageUser(ctx context.Context, in *ObjectUserCreateRequest) (*ObjectUser, error) {
@@ -236,21 +264,18 @@ func (client *IDCServicesClient) CreateObjectStorageUser(ctx context.Context, in
 		return nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "bucket user create api", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
-	tflog.Debug(ctx, "bucket user create api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	tflog.Debug(ctx, "bucket user create api", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(ctx, common.MakePOSTAPICall, parsedURL, inArgs)
+	tflog.Debug(ctx, "bucket user create api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading bucket user create response")
-	}
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading bucket user create response: %w", err)
 	}
 
 	objUser := &ObjectUser{}
 	if err := json.Unmarshal(retval, objUser); err != nil {
 		return nil, fmt.Errorf("error parsing bucket user response")
 	}
-	tflog.Debug(ctx, "bucket user create api", map[string]any{"retcode": retcode, "ret object": objUser})
+	tflog.Debug(ctx, "bucket user create api", map[string]any{"retcode": retcode, "userId": objUser.Metadata.UserId})
 	return objUser, nil
 }
 
@@ -271,22 +296,46 @@ func (client *IDCServicesClient) DeleteObjectUserByResourceId(ctx context.Contex
 		return fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting object bucket user by id")
+		return fmt.Errorf("error deleting object bucket user by id: %w", err)
 	}
 
-	tflog.Debug(ctx, "object bucket user delete api", map[string]any{"retcode": retcode, "retval": string(retval)})
-
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
-	}
+	tflog.Debug(ctx, "object bucket user delete api", map[string]any{"retcode": retcode})
 
 	tflog.Debug(ctx, "object bucket user delete api", map[string]any{"retcode": retcode})
 
 	return nil
 }
 
+func (client *IDCServicesClient) GetObjectUsers(ctx context.Context) (*ObjectUsers, error) {
+	params := struct {
+		Host         string
+		Cloudaccount string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(getAllObjectStorageUsersURL, params)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the url")
+	}
+
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading object users: %w", err)
+	}
+	tflog.Debug(ctx, "object users read api", map[string]any{"retcode": retcode})
+
+	users := ObjectUsers{}
+	if err := json.Unmarshal(retval, &users); err != nil {
+		return nil, fmt.Errorf("error parsing object users response")
+	}
+	return &users, nil
+}
+
 func (client *IDCServicesClient) GetObjectUserByUserId(ctx context.Context, userId string) (*ObjectUser, error) {
 	params := struct {
 		Host         string
@@ -304,13 +353,9 @@ func (client *IDCServicesClient) GetObjectUserByUserId(ctx context.Context, user
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading bucket user by id")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading bucket user by id: %w", err)
 	}
 
 	tflog.Debug(ctx, "object user read api", map[string]any{"retcode": retcode})
@@ -1,14 +1,16 @@
 package itacservices
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"strings"
 
 	"terraform-provider-intelcloud/pkg/itacservices/common"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
 )
 
 var (
@@ -60,13 +62,10 @@ func (client *IDCServicesClient) GetSSHKeys(ctx context.Context) (*SSHKeys, erro
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
-	tflog.Debug(ctx, "sshkeys read api", map[string]any{"retcode": retcode, "retval": string(retval)})
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	tflog.Debug(ctx, "sshkeys read api", map[string]any{"retcode": retcode})
 	if err != nil {
-		return nil, fmt.Errorf("error reading sshkeys")
-	}
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading sshkeys: %w", err)
 	}
 
 	sshkeys := SSHKeys{}
@@ -76,6 +75,39 @@ func (client *IDCServicesClient) GetSSHKeys(ctx context.Context) (*SSHKeys, erro
 	return &sshkeys, nil
 }
 
+// GetSSHKeyByName returns the key named name, nil if there is none.
+func (client *IDCServicesClient) GetSSHKeyByName(ctx context.Context, name string) (*SSHKey, error) {
+	sshkeys, err := client.GetSSHKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sshkeys.SSHKey {
+		if sshkeys.SSHKey[i].Metadata.Name == name {
+			return &sshkeys.SSHKey[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Matches reports whether the key is the one in would create.
+func (sshkey *SSHKey) Matches(in *SSHKeyCreateRequest) bool {
+	return sshkey.Metadata.Name == in.Metadata.Name &&
+		SameSSHPublicKey(sshkey.Spec.SSHPublicKey, in.Spec.SSHPublicKey) &&
+		sshkey.Spec.OwnerEmail == in.Spec.OwnerEmail
+}
+
+// SameSSHPublicKey reports whether the public keys in authorized_keys format
+// a and b are the same key, whatever their comments and surrounding
+// whitespace. Keys that do not parse are compared as text.
+func SameSSHPublicKey(a, b string) bool {
+	keyA, _, _, _, errA := ssh.ParseAuthorizedKey([]byte(a))
+	keyB, _, _, _, errB := ssh.ParseAuthorizedKey([]byte(b))
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return bytes.Equal(keyA.Marshal(), keyB.Marshal())
+}
+
 func (client *IDCServicesClient) CreateSSHkey(ctx context.Context, in *SSHKeyCreateRequest) (*SSHKey, error) {
 	params := struct {
 		Host         string
@@ -96,15 +128,21 @@ func (client *IDCServicesClient) CreateSSHkey(ctx context.Context, in *SSHKeyCre
 		return nil, fmt.Errorf("error parsing input arguments")
 	}
 
-	tflog.Debug(ctx, "sshkey create api request", map[string]any{"url": parsedURL, "inArgs": string(inArgs)})
-	retcode, retval, err := common.MakePOSTAPICall(ctx, parsedURL, *client.Apitoken, inArgs)
-	tflog.Debug(ctx, "sshkey create api response", map[string]any{"retcode": retcode, "retval": string(retval)})
-	if err != nil {
-		return nil, fmt.Errorf("error reading sshkey create response")
-	}
+	// A create that may have reached the API is only sent again if it did
+	// not make the key.
+	postCtx := common.WithResendCheck(ctx, func(ctx context.Context) ([]byte, error) {
+		sshkey, err := client.GetSSHKeyByName(ctx, in.Metadata.Name)
+		if err != nil || sshkey == nil || !sshkey.Matches(in) {
+			return nil, err
+		}
+		return json.Marshal(sshkey)
+	})
 
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+	tflog.Debug(ctx, "sshkey create api request", map[string]any{"url": parsedURL})
+	retcode, retval, err := client.callAPI(postCtx, common.MakePOSTAPICall, parsedURL, inArgs)
+	tflog.Debug(ctx, "sshkey create api response", map[string]any{"retcode": retcode})
+	if err != nil {
+		return nil, fmt.Errorf("error reading sshkey create response: %w", err)
 	}
 
 	sshkey := SSHKey{}
@@ -131,13 +169,9 @@ func (client *IDCServicesClient) GetSSHKeyByResourceId(ctx context.Context, reso
 		return nil, fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeGetAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading sshkey by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return nil, common.MapHttpError(retcode, retval)
+		return nil, fmt.Errorf("error reading sshkey by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "sshkey read api", map[string]any{"retcode": retcode})
@@ -165,13 +199,9 @@ func (client *IDCServicesClient) DeleteSSHKeyByResourceId(ctx context.Context, r
 		return fmt.Errorf("error parsing the url")
 	}
 
-	retcode, retval, err := common.MakeDeleteAPICall(ctx, parsedURL, *client.Apitoken, nil)
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
 	if err != nil {
-		return fmt.Errorf("error deleting sshkey by resource id")
-	}
-
-	if retcode != http.StatusOK {
-		return common.MapHttpError(retcode, retval)
+		return fmt.Errorf("error deleting sshkey by resource id: %w", err)
 	}
 
 	tflog.Debug(ctx, "sshkey delete api", map[string]any{"retcode": retcode})
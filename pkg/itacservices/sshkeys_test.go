@@ -0,0 +1,32 @@
+package itacservices
+
+import "testing"
+
+func TestSameSSHPublicKey(t *testing.T) {
+	const (
+		key      = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDqc7zJkGbI5H1bTXTmDqfpYtYaw5zTzzNQnOy3EhB1E user@example.com"
+		otherKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHq4ZRm1vPgxbDsxoJ2wXVtUFu8fN0Jq7bkQpLrS2cZY user@example.com"
+	)
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "identical", a: key, b: key, want: true},
+		{name: "trailing newline", a: key, b: key + "\n", want: true},
+		{name: "other comment", a: key, b: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDqc7zJkGbI5H1bTXTmDqfpYtYaw5zTzzNQnOy3EhB1E laptop", want: true},
+		{name: "no comment", a: key, b: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDqc7zJkGbI5H1bTXTmDqfpYtYaw5zTzzNQnOy3EhB1E", want: true},
+		{name: "other key", a: key, b: otherKey, want: false},
+		{name: "unparsable keys compared as text", a: "not a key\n", b: "not a key", want: true},
+		{name: "unparsable and parsable key", a: "not a key", b: key, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SameSSHPublicKey(tc.a, tc.b); got != tc.want {
+				t.Errorf("SameSSHPublicKey(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package itacservices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	getAllVNetsByAccount   = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets"
+	createVNetByAccount    = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets"
+	getVNetByResourceId    = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets/id/{{.ResourceId}}"
+	deleteVNetByResourceId = "{{.Host}}/v1/cloudaccounts/{{.Cloudaccount}}/vnets/id/{{.ResourceId}}"
+)
+
+// DefaultVNetPrefixLength is the prefix length of the vnets created when
+// none is set.
+const DefaultVNetPrefixLength = 24
+
+type VNets struct {
+	Vnets []VNet `json:"items"`
+}
+
+type VNet struct {
+	Metadata struct {
+		ResourceId   string `json:"resourceId"`
+		Cloudaccount string `json:"cloudAccountId"`
+		Name         string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		AvailabilityZone string `json:"availabilityZone"`
+		Region           string `json:"region"`
+		PrefixLength     int64  `json:"prefixLength"`
+	} `json:"spec"`
+}
+
+type VNetCreateRequest struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		AvailabilityZone string `json:"availabilityZone"`
+		Region           string `json:"region"`
+		PrefixLength     int64  `json:"prefixLength"`
+	} `json:"spec"`
+}
+
+// DefaultAvailabilityZone returns the availability zone resources of the
+// region of the client are created in when none is set, such as us-region-1a.
+func (client *IDCServicesClient) DefaultAvailabilityZone() string {
+	return *client.Region + "a"
+}
+
+// DefaultVNetName returns the name of the default vnet of the availability
+// zone, such as us-region-1a-default.
+func DefaultVNetName(availabilityZone string) string {
+	return availabilityZone + "-default"
+}
+
+// Matches reports whether the vnet is the one in would create.
+func (vnet *VNet) Matches(in *VNetCreateRequest) bool {
+	return vnet.Metadata.Name == in.Metadata.Name &&
+		vnet.Spec.AvailabilityZone == in.Spec.AvailabilityZone &&
+		vnet.Spec.PrefixLength == in.Spec.PrefixLength
+}
+
+func (client *IDCServicesClient) GetVNets(ctx context.Context) (*VNets, error) {
+	params := struct {
+		Host         string
+		Cloudaccount string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(getAllVNetsByAccount, params)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the url")
+	}
+	tflog.Debug(ctx, "vnets get api request", map[string]any{"url": parsedURL})
+
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	if err != nil {
+		tflog.Debug(ctx, "vnet get response", map[string]any{"retcode": retcode, "error": err})
+		return nil, fmt.Errorf("error reading vnets get response: %w", err)
+	}
+
+	vnets := VNets{}
+	if err := json.Unmarshal(retval, &vnets); err != nil {
+		return nil, fmt.Errorf("error parsing vnets response")
+	}
+	tflog.Debug(ctx, "vnets get api response", map[string]any{"retcode": retcode, "count": len(vnets.Vnets)})
+	return &vnets, nil
+}
+
+// GetVNetByName returns the vnet named name, nil if there is none.
+func (client *IDCServicesClient) GetVNetByName(ctx context.Context, name string) (*VNet, error) {
+	vnets, err := client.GetVNets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vnets.Vnets {
+		if vnets.Vnets[i].Metadata.Name == name {
+			return &vnets.Vnets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (client *IDCServicesClient) GetVNetByResourceId(ctx context.Context, resourceId string) (*VNet, error) {
+	params := struct {
+		Host         string
+		Cloudaccount string
+		ResourceId   string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+		ResourceId:   resourceId,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(getVNetByResourceId, params)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the url")
+	}
+
+	retcode, retval, err := client.callAPI(ctx, common.MakeGetAPICall, parsedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading vnet by resource id: %w", err)
+	}
+
+	tflog.Debug(ctx, "vnet read api", map[string]any{"retcode": retcode})
+	vnet := VNet{}
+	if err := json.Unmarshal(retval, &vnet); err != nil {
+		return nil, fmt.Errorf("error parsing vnet response")
+	}
+	return &vnet, nil
+}
+
+func (client *IDCServicesClient) CreateVNet(ctx context.Context, in *VNetCreateRequest) (*VNet, error) {
+	params := struct {
+		Host         string
+		Cloudaccount string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(createVNetByAccount, params)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the url")
+	}
+
+	payload, err := json.MarshalIndent(in, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing input arguments")
+	}
+
+	// A create that may have reached the API is only sent again if it did
+	// not make the vnet.
+	postCtx := common.WithResendCheck(ctx, func(ctx context.Context) ([]byte, error) {
+		vnet, err := client.GetVNetByName(ctx, in.Metadata.Name)
+		if err != nil || vnet == nil || !vnet.Matches(in) {
+			return nil, err
+		}
+		return json.Marshal(vnet)
+	})
+
+	retcode, retval, err := client.callAPI(postCtx, common.MakePOSTAPICall, parsedURL, payload)
+	if err != nil {
+		return nil, fmt.Errorf("error reading vnet create response: %w", err)
+	}
+
+	vnet := VNet{}
+	if err := json.Unmarshal(retval, &vnet); err != nil {
+		return nil, fmt.Errorf("error parsing vnet response")
+	}
+	tflog.Debug(ctx, "vnet create api response", map[string]any{"retcode": retcode, "resourceId": vnet.Metadata.ResourceId})
+	return &vnet, nil
+}
+
+func (client *IDCServicesClient) DeleteVNetByResourceId(ctx context.Context, resourceId string) error {
+	params := struct {
+		Host         string
+		Cloudaccount string
+		ResourceId   string
+	}{
+		Host:         *client.Host,
+		Cloudaccount: *client.Cloudaccount,
+		ResourceId:   resourceId,
+	}
+
+	// Parse the template string with the provided data
+	parsedURL, err := common.ParseString(deleteVNetByResourceId, params)
+	if err != nil {
+		return fmt.Errorf("error parsing the url")
+	}
+
+	retcode, _, err := client.callAPI(ctx, common.MakeDeleteAPICall, parsedURL, nil)
+	if err != nil {
+		return fmt.Errorf("error deleting vnet by resource id: %w", err)
+	}
+
+	tflog.Debug(ctx, "vnet delete api", map[string]any{"retcode": retcode})
+	return nil
+}
+
+// CreateVNetIfNotFound returns the default vnet of the availability zone,
+// creating it if it does not exist.
+func (client *IDCServicesClient) CreateVNetIfNotFound(ctx context.Context, zone string) (*VNet, error) {
+	vnet, err := client.GetVNetByName(ctx, DefaultVNetName(zone))
+	if err != nil {
+		return nil, err
+	}
+	if vnet != nil {
+		return vnet, nil
+	}
+
+	tflog.Debug(ctx, "default vnet not found, creating a new", map[string]any{"availabilityZone": zone})
+	inArgs := VNetCreateRequest{}
+	inArgs.Metadata.Name = DefaultVNetName(zone)
+	inArgs.Spec.AvailabilityZone = zone
+	inArgs.Spec.Region = *client.Region
+	inArgs.Spec.PrefixLength = DefaultVNetPrefixLength
+	return client.CreateVNet(ctx, &inArgs)
+}
@@ -0,0 +1,101 @@
+package itacservices
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+)
+
+func TestCreateVNetIfNotFound(t *testing.T) {
+	const vnetsPath = "/v1/cloudaccounts/" + testCloudaccount + "/vnets"
+
+	tests := []struct {
+		name     string
+		steps    []apiStep
+		wantName string
+		wantErr  func(error) bool
+	}{
+		{
+			name: "existing vnet",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK,
+					response: `{"items": [{"metadata": {"name": "us-staging-1a-default", "resourceId": "v1"}}, {"metadata": {"name": "other"}}]}`},
+			},
+			wantName: "us-staging-1a-default",
+		},
+		{
+			name: "default vnet picked among others",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK,
+					response: `{"items": [{"metadata": {"name": "other", "resourceId": "v0"}}, {"metadata": {"name": "us-staging-1a-default", "resourceId": "v1"}}]}`},
+			},
+			wantName: "us-staging-1a-default",
+		},
+		{
+			name: "default vnet created when only others exist",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK,
+					response: `{"items": [{"metadata": {"name": "other", "resourceId": "v0"}}]}`},
+				{method: http.MethodPost, path: vnetsPath,
+					body:   `{"metadata": {"name": "us-staging-1a-default"}, "spec": {"availabilityZone": "us-staging-1a", "region": "us-staging-1", "prefixLength": 24}}`,
+					status: http.StatusOK, response: `{"metadata": {"name": "us-staging-1a-default", "resourceId": "v2"}}`},
+			},
+			wantName: "us-staging-1a-default",
+		},
+		{
+			name: "vnet created when none exists",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK, response: `{"items": []}`},
+				{method: http.MethodPost, path: vnetsPath,
+					body:   `{"metadata": {"name": "us-staging-1a-default"}, "spec": {"availabilityZone": "us-staging-1a", "region": "us-staging-1", "prefixLength": 24}}`,
+					status: http.StatusOK, response: `{"metadata": {"name": "us-staging-1a-default", "resourceId": "v2"}}`},
+			},
+			wantName: "us-staging-1a-default",
+		},
+		{
+			name: "list forbidden",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusForbidden, response: errorBody(7, "denied")},
+			},
+			wantErr: isError[*common.ForbiddenError],
+		},
+		{
+			name: "create conflict",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK, response: `{"items": []}`},
+				{method: http.MethodPost, path: vnetsPath, status: http.StatusConflict, response: errorBody(6, "exists")},
+			},
+			wantErr: isError[*common.ConflictError],
+		},
+		{
+			name: "malformed list",
+			steps: []apiStep{
+				{method: http.MethodGet, path: vnetsPath, status: http.StatusOK, response: `{"items": [`},
+			},
+			wantErr: anyError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, script := newScriptedClient(t, "us-staging-1", tc.steps)
+
+			vnet, err := client.CreateVNetIfNotFound(context.Background(), client.DefaultAvailabilityZone())
+			if tc.wantErr != nil {
+				if !tc.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script.done()
+			if vnet.Metadata.Name != tc.wantName {
+				t.Errorf("vnet name = %q, want %q", vnet.Metadata.Name, tc.wantName)
+			}
+		})
+	}
+}
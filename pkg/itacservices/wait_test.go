@@ -0,0 +1,374 @@
+package itacservices
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"terraform-provider-intelcloud/pkg/itacservices/common"
+	"terraform-provider-intelcloud/pkg/itacservices/itacfake"
+)
+
+const (
+	instancePath  = "/v1/cloudaccounts/*/instances/id/*"
+	clusterPath   = "/v1/cloudaccounts/*/iks/clusters/*"
+	nodeGroupPath = "/v1/cloudaccounts/*/iks/clusters/*/nodegroups/*"
+)
+
+// waitCase is a scenario for the wait loop of a create call. The call is
+// made with a context bounded by timeout.
+type waitCase struct {
+	name    string
+	setup   func(srv *itacfake.Server)
+	timeout time.Duration
+	wantErr bool
+	// checkErr, if set, checks the error of the call.
+	checkErr func(t *testing.T, err error)
+	// minPolls is the least number of reads the wait loop must make.
+	minPolls int
+}
+
+// checkFailed checks that err reports the failure of the resource with the
+// message of the fake server.
+func checkFailed(t *testing.T, err error) {
+	var provErr *common.ProvisioningError
+	if !errors.As(err, &provErr) {
+		t.Fatalf("error %v is not a ProvisioningError", err)
+	}
+	if provErr.Err != nil || provErr.Phase != "Failed" || provErr.Message != itacfake.FailureMessage {
+		t.Errorf("error = %+v, want the Failed phase with message %q", provErr, itacfake.FailureMessage)
+	}
+}
+
+// checkTimedOut checks that err reports the phase the resource was stuck in
+// when the deadline ran out.
+func checkTimedOut(t *testing.T, err error) {
+	var provErr *common.ProvisioningError
+	if !errors.As(err, &provErr) {
+		t.Fatalf("error %v is not a ProvisioningError", err)
+	}
+	if !isDeadlineExceeded(err) || provErr.Phase == "" {
+		t.Errorf("error = %v, want the last phase and the deadline", err)
+	}
+}
+
+// waitCases returns the scenarios shared by every wait loop, for resources
+// of kind named name read at path.
+func waitCases(kind itacfake.Kind, name, path string) []waitCase {
+	return []waitCase{
+		{
+			name:     "ready after provisioning",
+			timeout:  5 * time.Second,
+			minPolls: 2,
+		},
+		{
+			name: "failed phase",
+			setup: func(srv *itacfake.Server) {
+				srv.SetPhaseBehavior(kind, name, itacfake.PhaseFail)
+			},
+			timeout:  5 * time.Second,
+			wantErr:  true,
+			checkErr: checkFailed,
+		},
+		{
+			name: "stuck phase",
+			setup: func(srv *itacfake.Server) {
+				srv.SetPhaseBehavior(kind, name, itacfake.PhaseStuck)
+			},
+			timeout:  300 * time.Millisecond,
+			wantErr:  true,
+			checkErr: checkTimedOut,
+			minPolls: 2,
+		},
+		{
+			name: "transient unavailability",
+			setup: func(srv *itacfake.Server) {
+				srv.InjectFault(itacfake.Fault{Method: http.MethodGet, Path: path, Status: http.StatusServiceUnavailable, Times: 2})
+			},
+			timeout:  5 * time.Second,
+			minPolls: 3,
+		},
+		{
+			name: "rate limited",
+			setup: func(srv *itacfake.Server) {
+				srv.InjectFault(itacfake.Fault{Method: http.MethodGet, Path: path, Status: http.StatusTooManyRequests, RetryAfter: 1, Times: 1})
+			},
+			timeout:  5 * time.Second,
+			minPolls: 2,
+		},
+		{
+			name: "internal server error",
+			setup: func(srv *itacfake.Server) {
+				srv.InjectFault(itacfake.Fault{Method: http.MethodGet, Path: path, Status: http.StatusInternalServerError})
+			},
+			timeout: 5 * time.Second,
+			wantErr: true,
+		},
+		{
+			name: "malformed response",
+			setup: func(srv *itacfake.Server) {
+				srv.InjectFault(itacfake.Fault{Method: http.MethodGet, Path: path, MalformedJSON: true})
+			},
+			timeout: 5 * time.Second,
+			wantErr: true,
+		},
+		{
+			name: "slow response",
+			setup: func(srv *itacfake.Server) {
+				srv.InjectFault(itacfake.Fault{Method: http.MethodGet, Path: path, Latency: time.Second})
+			},
+			timeout: 200 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+}
+
+func newWaitTestClient(t *testing.T, srv *itacfake.Server) *IDCServicesClient {
+	t.Helper()
+
+	saved := pollInterval
+	pollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollInterval = saved })
+
+	host, account, region := srv.URL, "123456789012", "us-fake-1"
+	id, secret := "client-id", "client-secret"
+	client, err := NewClient(context.Background(), &host, &host, &account, &id, &secret, &region)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	client.RetryPolicy = &common.RetryPolicy{MaxRetries: 3, MinWait: time.Millisecond, MaxWait: 10 * time.Millisecond}
+	return client
+}
+
+// runWaitCase runs the create call of tc against a fresh fake server and
+// checks the outcome. prepare creates what the call depends on before the
+// scenario is set up.
+func runWaitCase(t *testing.T, tc waitCase, path string,
+	prepare func(ctx context.Context, client *IDCServicesClient) error,
+	create func(ctx context.Context, client *IDCServicesClient) error) {
+	srv := itacfake.New(itacfake.Config{ProvisioningDelay: 30 * time.Millisecond})
+	defer srv.Close()
+	client := newWaitTestClient(t, srv)
+
+	if prepare != nil {
+		if err := prepare(context.Background(), client); err != nil {
+			t.Fatalf("preparing: %v", err)
+		}
+	}
+	if tc.setup != nil {
+		tc.setup(srv)
+	}
+	polls := srv.RequestCount(http.MethodGet, path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+	defer cancel()
+	start := time.Now()
+	err := create(ctx, client)
+	elapsed := time.Since(start)
+
+	if tc.wantErr && err == nil {
+		t.Fatal("expected an error")
+	}
+	if !tc.wantErr && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tc.checkErr != nil {
+		tc.checkErr(t, err)
+	}
+	if elapsed > tc.timeout+time.Second {
+		t.Errorf("call returned after %s, beyond its %s deadline", elapsed, tc.timeout)
+	}
+	if got := srv.RequestCount(http.MethodGet, path) - polls; got < tc.minPolls {
+		t.Errorf("read the resource %d times, want at least %d", got, tc.minPolls)
+	}
+}
+
+func TestCreateInstanceWait(t *testing.T) {
+	for _, tc := range waitCases(itacfake.KindInstance, "vm", instancePath) {
+		t.Run(tc.name, func(t *testing.T) {
+			runWaitCase(t, tc, instancePath, nil, func(ctx context.Context, client *IDCServicesClient) error {
+				in := &InstanceCreateRequest{}
+				in.Metadata.Name = "vm"
+				in.Spec.InstanceType = "vm-spr-sml"
+				in.Spec.MachineImage = "ubuntu-2204-jammy-v20240308"
+				instance, err := client.CreateInstance(ctx, in, false)
+				if err == nil && instance.Status.Phase != "Ready" {
+					t.Errorf("phase = %q, want Ready", instance.Status.Phase)
+				}
+				return err
+			})
+		})
+	}
+}
+
+func TestCreateIKSClusterWait(t *testing.T) {
+	for _, tc := range waitCases(itacfake.KindCluster, "cluster", clusterPath) {
+		t.Run(tc.name, func(t *testing.T) {
+			runWaitCase(t, tc, clusterPath, nil, func(ctx context.Context, client *IDCServicesClient) error {
+				cluster, _, err := client.CreateIKSCluster(ctx, &IKSCreateRequest{
+					Name:        "cluster",
+					K8sVersion:  "1.28",
+					RuntimeName: "Containerd",
+				}, false)
+				if err == nil && cluster.ClusterState != "Active" {
+					t.Errorf("cluster state = %q, want Active", cluster.ClusterState)
+				}
+				return err
+			})
+		})
+	}
+}
+
+func TestCreateIKSNodeGroupWait(t *testing.T) {
+	for _, tc := range waitCases(itacfake.KindNodeGroup, "ng", nodeGroupPath) {
+		t.Run(tc.name, func(t *testing.T) {
+			var clusterUUID string
+			prepare := func(ctx context.Context, client *IDCServicesClient) error {
+				cluster, _, err := client.CreateIKSCluster(ctx, &IKSCreateRequest{Name: "cluster", K8sVersion: "1.28"}, false)
+				if err != nil {
+					return err
+				}
+				clusterUUID = cluster.ResourceId
+				return nil
+			}
+			runWaitCase(t, tc, nodeGroupPath, prepare, func(ctx context.Context, client *IDCServicesClient) error {
+				ng, _, err := client.CreateIKSNodeGroup(ctx, &IKSNodeGroupCreateRequest{
+					Name:           "ng",
+					Count:          1,
+					InstanceTypeId: "vm-spr-sml",
+				}, clusterUUID, false)
+				if err == nil && ng.State != "Active" {
+					t.Errorf("node group state = %q, want Active", ng.State)
+				}
+				return err
+			})
+		})
+	}
+}
+
+// deleteCase is a scenario for the wait loop of a delete call, against a
+// fake server keeping deleted resources for deletionDelay.
+type deleteCase struct {
+	name          string
+	deletionDelay time.Duration
+	timeout       time.Duration
+	// unknown deletes a resource that does not exist.
+	unknown bool
+	wantErr func(error) bool
+}
+
+var deleteCases = []deleteCase{
+	{
+		name:          "gone after deleting",
+		deletionDelay: 50 * time.Millisecond,
+		timeout:       5 * time.Second,
+	},
+	{
+		name:    "gone immediately",
+		timeout: 5 * time.Second,
+	},
+	{
+		name:          "still deleting at the deadline",
+		deletionDelay: time.Hour,
+		timeout:       200 * time.Millisecond,
+		wantErr:       isDeadlineExceeded,
+	},
+	{
+		name:    "unknown resource",
+		unknown: true,
+		timeout: 5 * time.Second,
+		wantErr: common.IsNotFound,
+	},
+}
+
+// runDeleteCase creates a resource with create, which returns its id, and
+// deletes it with del under the scenario of tc.
+func runDeleteCase(t *testing.T, tc deleteCase,
+	create func(ctx context.Context, client *IDCServicesClient) (string, error),
+	del func(ctx context.Context, client *IDCServicesClient, id string) error) {
+	srv := itacfake.New(itacfake.Config{DeletionDelay: tc.deletionDelay})
+	defer srv.Close()
+	client := newWaitTestClient(t, srv)
+
+	id, err := create(context.Background(), client)
+	if err != nil {
+		t.Fatalf("creating: %v", err)
+	}
+	if tc.unknown {
+		id = "unknown"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tc.timeout)
+	defer cancel()
+	err = del(ctx, client, id)
+
+	if tc.wantErr != nil {
+		if !tc.wantErr(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteInstanceWait(t *testing.T) {
+	for _, tc := range deleteCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runDeleteCase(t, tc, func(ctx context.Context, client *IDCServicesClient) (string, error) {
+				in := &InstanceCreateRequest{}
+				in.Metadata.Name = "vm"
+				in.Spec.InstanceType = "vm-spr-sml"
+				in.Spec.MachineImage = "ubuntu-2204-jammy-v20240308"
+				instance, err := client.CreateInstance(ctx, in, false)
+				if err != nil {
+					return "", err
+				}
+				return instance.Metadata.ResourceId, nil
+			}, func(ctx context.Context, client *IDCServicesClient, id string) error {
+				if err := client.DeleteInstanceByResourceId(ctx, id); err != nil {
+					return err
+				}
+				if _, err := client.GetInstanceByResourceId(ctx, id); !common.IsNotFound(err) {
+					t.Errorf("instance still readable after delete: %v", err)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestDeleteIKSNodeGroupWait(t *testing.T) {
+	for _, tc := range deleteCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var clusterUUID string
+			runDeleteCase(t, tc, func(ctx context.Context, client *IDCServicesClient) (string, error) {
+				cluster, _, err := client.CreateIKSCluster(ctx, &IKSCreateRequest{Name: "cluster", K8sVersion: "1.28"}, false)
+				if err != nil {
+					return "", err
+				}
+				clusterUUID = cluster.ResourceId
+				ng, _, err := client.CreateIKSNodeGroup(ctx, &IKSNodeGroupCreateRequest{
+					Name:           "ng",
+					Count:          1,
+					InstanceTypeId: "vm-spr-sml",
+				}, clusterUUID, false)
+				if err != nil {
+					return "", err
+				}
+				return ng.ID, nil
+			}, func(ctx context.Context, client *IDCServicesClient, id string) error {
+				if err := client.DeleteIKSNodeGroup(ctx, clusterUUID, id); err != nil {
+					return err
+				}
+				if _, _, err := client.GetIKSNodeGroupByID(ctx, clusterUUID, id); !common.IsNotFound(err) {
+					t.Errorf("node group still readable after delete: %v", err)
+				}
+				return nil
+			})
+		})
+	}
+}